@@ -1,16 +1,88 @@
 package main
 
 import (
+	"automation-hub-backend/internal/admin"
+	"automation-hub-backend/internal/automation"
 	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/gateway"
+	"automation-hub-backend/internal/healthcheck"
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/maintenance"
+	"automation-hub-backend/internal/metrics"
+	"automation-hub-backend/internal/notification"
+	"automation-hub-backend/internal/outbox"
 	"automation-hub-backend/internal/router"
+	"automation-hub-backend/internal/tracing"
+	"automation-hub-backend/internal/uptime"
+	"context"
+	"log"
 )
 
 func main() {
 	config.Init()
 
-	err := router.Initialize()
+	ctx := context.Background()
+
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+
+	// the probes are served while the database is opened and migrated
+	server := router.NewServer()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Run()
+	}()
+	if _, err := infra.WaitForDefaultDB(ctx); err != nil {
+		log.Fatalf("Failed to open the database: %v", err)
+	}
+
+	notifier := notification.DefaultNotifier()
+
+	relay := outbox.DefaultRelay()
+	relay.AddPublisher(notifier)
+	go relay.Run(ctx)
+
+	if config.AppConfig.GatewayEnabled {
+		go gateway.DefaultGateway().Run(ctx)
+	}
+
+	snapshotJob := admin.DefaultSnapshotJob()
+	go snapshotJob.Run(ctx)
+
+	purgeJob := automation.DefaultPurgeJob()
+	go purgeJob.Run(ctx)
+
+	scheduler := maintenance.DefaultScheduler()
+	go scheduler.Run(ctx)
+
+	if config.AppConfig.HealthCheckEnabled {
+		prober := healthcheck.DefaultProber()
+		prober.AddObserver(uptime.DefaultRecorder())
+		prober.AddObserver(notifier)
+		go prober.Run(ctx)
+
+		rollupJob := uptime.DefaultRollupJob()
+		go rollupJob.Run(ctx)
+	}
+
+	metrics.RegisterHealthCounter(healthcheck.DefaultRepository().CountByStatus)
+
+	dispatcher := notification.DefaultDispatcher()
+	go dispatcher.Run(ctx)
+
+	err = server.Mount()
 	if err != nil {
 		panic(err)
 	}
 
+	if err := <-serverErr; err != nil {
+		panic(err)
+	}
 }
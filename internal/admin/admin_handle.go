@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// Snapshot
+// @Summary Publish a snapshot
+// @Description Publish a snapshot event per workspace with its complete ordered automation list
+// @Tags Admin
+// @Produce  json
+// @Success 202 "Snapshot queued"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/snapshot [post]
+func (h *Handler) Snapshot(c *gin.Context) {
+	err := h.service.Snapshot(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// Replay
+// @Summary Replay the latest event of every automation
+// @Description Re-emit the latest event of every automation, optionally to the log-compacted topic
+// @Tags Admin
+// @Produce  json
+// @Param target query string false "Replay target (default or compacted)"
+// @Success 202 {object} map[string]int "Number of replayed automations"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/replay [post]
+func (h *Handler) Replay(c *gin.Context) {
+	target := c.DefaultQuery("target", DefaultTarget)
+
+	replayed, err := h.service.Replay(c.Request.Context(), target)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"replayed": replayed})
+}
+
+// ReplayAutomation
+// @Summary Replay the latest event of an automation
+// @Description Re-emit the latest event of a specific automation, optionally to the log-compacted topic
+// @Tags Admin
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param target query string false "Replay target (default or compacted)"
+// @Success 202 "Event replayed"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/replay/{id} [post]
+func (h *Handler) ReplayAutomation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	target := c.DefaultQuery("target", DefaultTarget)
+
+	err = h.service.ReplayAutomation(c.Request.Context(), id, target)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrCompactedDisabled), errors.Is(err, ErrUnknownReplayTarget):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/outbox"
+	"automation-hub-backend/internal/workspace"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTarget re-emits through the outbox to the configured transport.
+	DefaultTarget = "default"
+	// CompactedTarget publishes directly to the log-compacted Kafka topic.
+	CompactedTarget = "compacted"
+)
+
+var (
+	ErrNotFound            = errors.New("automation not found")
+	ErrCompactedDisabled   = errors.New("no compacted topic is configured")
+	ErrUnknownReplayTarget = errors.New("unknown replay target")
+)
+
+type Service interface {
+	Snapshot(ctx context.Context) error
+	SnapshotIfDue(ctx context.Context, interval time.Duration) (bool, error)
+	Replay(ctx context.Context, target string) (int, error)
+	ReplayAutomation(ctx context.Context, id uuid.UUID, target string) error
+}
+
+type service struct {
+	repo       automation.Repository
+	workspaces workspace.Repository
+	outbox     outbox.Repository
+	compacted  events.Publisher
+}
+
+// NewService builds the admin service. compacted may be nil when no
+// log-compacted topic is configured.
+func NewService(repo automation.Repository, workspaceRepo workspace.Repository, outboxRepo outbox.Repository,
+	compacted events.Publisher) Service {
+	return &service{
+		repo:       repo,
+		workspaces: workspaceRepo,
+		outbox:     outboxRepo,
+		compacted:  compacted,
+	}
+}
+
+var (
+	defaultService     Service
+	defaultServiceOnce sync.Once
+)
+
+// DefaultService returns the service shared by the API and the snapshot job,
+// so that both use the same compacted topic producer.
+func DefaultService() Service {
+	defaultServiceOnce.Do(func() {
+		var compacted events.Publisher
+		if config.AppConfig.CompactedTopic != "" {
+			publisher, err := events.NewCompactedKafkaPublisher(config.AppConfig.Brokers,
+				config.AppConfig.CompactedTopic, config.AppConfig.EventEncoding)
+			if err != nil {
+				log.Printf("Failed to create compacted topic publisher: %v", err)
+			} else {
+				compacted = events.NewInstrumentedPublisher("kafka_compacted", publisher)
+			}
+		}
+		defaultService = NewService(automation.DefaultRepository(), workspace.DefaultRepository(),
+			outbox.DefaultRepository(), compacted)
+	})
+	return defaultService
+}
+
+// Snapshot queues one snapshot event per workspace.
+func (s *service) Snapshot(ctx context.Context) error {
+	return s.snapshot(ctx, s.outbox)
+}
+
+// SnapshotIfDue queues a snapshot unless one was queued within interval.
+// Replicas take turns through an advisory lock and see each other's
+// snapshots in the outbox, so one snapshot is queued per interval.
+func (s *service) SnapshotIfDue(ctx context.Context, interval time.Duration) (bool, error) {
+	queued := false
+	_, err := s.outbox.WithSnapshotLock(func(repo outbox.Repository) error {
+		latest, err := repo.FindLatestOfType(string(events.SnapshotEvent))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if latest != nil && time.Since(latest.CreatedAt) < interval {
+			return nil
+		}
+		queued = true
+		return s.snapshot(ctx, repo)
+	})
+	if err != nil {
+		return false, err
+	}
+	return queued, nil
+}
+
+func (s *service) snapshot(ctx context.Context, outboxRepo outbox.Repository) error {
+	workspaces, err := s.workspaces.FindAll()
+	if err != nil {
+		return err
+	}
+	for _, ws := range workspaces {
+		automations, err := s.repo.InWorkspace(ws.ID).FindAll()
+		if err != nil {
+			return err
+		}
+		if err := outboxRepo.Enqueue(events.NewSnapshotEvent(ctx, ws.Slug, automations)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Replay(ctx context.Context, target string) (int, error) {
+	if err := s.checkTarget(target); err != nil {
+		return 0, err
+	}
+
+	replayed := make(map[string]bool)
+	messages, err := s.outbox.FindLatestPerAggregate()
+	if err != nil {
+		return 0, err
+	}
+	for _, message := range messages {
+		event, errDecode := outbox.Decode(message)
+		if errDecode != nil {
+			return len(replayed), errDecode
+		}
+		if event.Automation == nil {
+			continue
+		}
+		if err := s.emit(ctx, event, target); err != nil {
+			return len(replayed), err
+		}
+		replayed[message.AggregateID] = true
+	}
+
+	// Automations whose events predate the outbox are re-emitted from their
+	// current state.
+	automations, err := s.repo.FindAll()
+	if err != nil {
+		return len(replayed), err
+	}
+	for _, current := range automations {
+		if replayed[current.ID.String()] {
+			continue
+		}
+		if err := s.emit(ctx, events.NewAutomationEvent(ctx, events.UpdateEvent, current), target); err != nil {
+			return len(replayed), err
+		}
+		replayed[current.ID.String()] = true
+	}
+
+	return len(replayed), nil
+}
+
+func (s *service) ReplayAutomation(ctx context.Context, id uuid.UUID, target string) error {
+	if err := s.checkTarget(target); err != nil {
+		return err
+	}
+
+	message, err := s.outbox.FindLatest(id.String())
+	if err == nil {
+		event, errDecode := outbox.Decode(message)
+		if errDecode != nil {
+			return errDecode
+		}
+		return s.emit(ctx, event, target)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return s.emit(ctx, events.NewAutomationEvent(ctx, events.UpdateEvent, current), target)
+}
+
+// emit sends a copy of event under a new ID, so that consumers deduping on the
+// CloudEvents id do not discard the replay.
+func (s *service) emit(ctx context.Context, event *events.AutomationEvent, target string) error {
+	replay := *event
+	replay.ID = uuid.New()
+	replay.Time = time.Now().UTC()
+	replay.Replay = true
+	if actor := events.ActorFromContext(ctx); actor != "" {
+		replay.Actor = actor
+	}
+
+	if target == CompactedTarget {
+		return s.compacted.Publish(&replay)
+	}
+	return s.outbox.Enqueue(&replay)
+}
+
+func (s *service) checkTarget(target string) error {
+	switch target {
+	case DefaultTarget:
+		return nil
+	case CompactedTarget:
+		if s.compacted == nil {
+			return ErrCompactedDisabled
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownReplayTarget, target)
+	}
+}
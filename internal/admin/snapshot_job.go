@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"automation-hub-backend/internal/config"
+	"context"
+	"log"
+	"time"
+)
+
+// SnapshotJob periodically publishes a full-state snapshot so that consumers
+// which lost their state converge without operator action. Every replica
+// runs the job, so it checks often whether the last snapshot of any replica
+// is older than the interval rather than snapshotting on its own schedule.
+type SnapshotJob struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewSnapshotJob(service Service, interval time.Duration) *SnapshotJob {
+	return &SnapshotJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func DefaultSnapshotJob() *SnapshotJob {
+	return NewSnapshotJob(DefaultService(), config.AppConfig.SnapshotInterval)
+}
+
+func (j *SnapshotJob) Run(ctx context.Context) {
+	if j.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.checkInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.service.SnapshotIfDue(ctx, j.interval); err != nil {
+				log.Printf("Failed to publish snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// checkInterval is how often the job looks for a due snapshot, so that
+// snapshots are at most a tenth of the interval late.
+func (j *SnapshotJob) checkInterval() time.Duration {
+	check := j.interval / 10
+	if check < time.Second {
+		check = time.Second
+	}
+	return check
+}
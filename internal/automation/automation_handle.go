@@ -3,14 +3,24 @@ package automation
 import (
 	"automation-hub-backend/internal/config"
 	"automation-hub-backend/internal/models"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// StateChange is the lifecycle state an automation is moved into. Message
+// and ETA are shown on the maintenance page.
+type StateChange struct {
+	State   string     `json:"state"`
+	Message string     `json:"message,omitempty"`
+	ETA     *time.Time `json:"eta,omitempty"`
+}
+
 type Handler struct {
 	service Service
 }
@@ -43,6 +53,16 @@ func (h *Handler) ImageHandler(c *gin.Context) {
 // @Param port formData int true "Automation Port"
 // @Param position formData int true "Automation Position"
 // @Param removeImage formData bool true "Remove Image"
+// @Param routingOptions formData string false "Routing options as JSON"
+// @Param loadBalancing formData string false "Load-balancing policy across the targets"
+// @Param categoryId formData string false "Category ID"
+// @Param description formData string false "Description in markdown"
+// @Param owner formData string false "Owner"
+// @Param team formData string false "Team"
+// @Param contactEmail formData string false "Contact email address"
+// @Param documentationUrl formData string false "Documentation URL"
+// @Param sourceRepoUrl formData string false "Source repository URL"
+// @Param labels formData string false "Labels as a JSON object of strings"
 // @Param id formData string false "Automation ID"
 // @Param imageFile formData file false "Image File"
 // @Success 201 {object} models.Automation "Successfully created automation"
@@ -58,6 +78,35 @@ func (h *Handler) Create(c *gin.Context) {
 	automation.Port = port
 	removeImage, _ := strconv.ParseBool(c.PostForm("removeImage"))
 	automation.RemoveImage = removeImage
+	automation.LoadBalancing = c.PostForm("loadBalancing")
+	if routingOptions := c.PostForm("routingOptions"); routingOptions != "" {
+		if err := models.JSON.UnmarshalFromString(routingOptions, &automation.Routing); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid routingOptions: " + err.Error()})
+			return
+		}
+	}
+
+	automation.Description = c.PostForm("description")
+	automation.Owner = c.PostForm("owner")
+	automation.Team = c.PostForm("team")
+	automation.ContactEmail = c.PostForm("contactEmail")
+	automation.DocumentationURL = c.PostForm("documentationUrl")
+	automation.SourceRepoURL = c.PostForm("sourceRepoUrl")
+	if labels := c.PostForm("labels"); labels != "" {
+		if err := models.JSON.UnmarshalFromString(labels, &automation.Labels); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid labels: " + err.Error()})
+			return
+		}
+	}
+
+	if categoryID := c.PostForm("categoryId"); categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid categoryId format"})
+			return
+		}
+		automation.CategoryID = &id
+	}
 
 	file, _ := c.FormFile("imageFile")
 	if file != nil {
@@ -71,9 +120,9 @@ func (h *Handler) Create(c *gin.Context) {
 		log.Println("No image file received")
 	}
 
-	newAutomation, err := h.service.Create(&automation)
+	newAutomation, err := h.service.Create(c.Request.Context(), &automation)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, newAutomation)
@@ -81,15 +130,35 @@ func (h *Handler) Create(c *gin.Context) {
 
 // GetAll
 // @Summary Get all automations
-// @Description Retrieve all automations
+// @Description Retrieve all automations, optionally only those carrying every given tag, in the given category and state and matching the label selector
 // @Tags Automations
 // @Accept  json
 // @Produce  json
+// @Param tag query []string false "Tag name; repeat to require several tags" collectionFormat(multi)
+// @Param category query string false "Category name"
+// @Param state query string false "Lifecycle state: active, disabled or maintenance"
+// @Param labels query string false "Label selector such as env=prod,tier!=batch,canary,!legacy"
 // @Success 200 {array} models.Automation "Successfully retrieved automations"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /automations [get]
 func (h *Handler) GetAll(c *gin.Context) {
-	automations, err := h.service.FindAll()
+	labels, err := ParseLabelSelector(c.Query("labels"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	state := c.Query("state")
+	if state != "" && !models.ValidState(state) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid state " + state})
+		return
+	}
+	filter := Filter{
+		Tags:     c.QueryArray("tag"),
+		Category: c.Query("category"),
+		State:    state,
+		Labels:   labels,
+	}
+	automations, err := h.service.FindAll(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -118,7 +187,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 		return
 	}
 
-	automation, err := h.service.FindByID(id)
+	automation, err := h.service.FindByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -134,7 +203,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 // DeleteByID
 // @Summary Delete an automation by ID
-// @Description Delete a specific automation by its ID
+// @Description Move a specific automation to the trash, from which it can be restored until it is purged
 // @Tags Automations
 // @Accept  json
 // @Produce  json
@@ -152,7 +221,7 @@ func (h *Handler) DeleteByID(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -190,7 +259,7 @@ func (h *Handler) SwapPosition(c *gin.Context) {
 		return
 	}
 
-	err = h.service.SwapOrder(id1, id2)
+	err = h.service.SwapOrder(c.Request.Context(), id1, id2)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -226,11 +295,230 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
-	updatedAutomation, err := h.service.Update(&automation)
+	updatedAutomation, err := h.service.Update(c.Request.Context(), &automation)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedAutomation)
 }
+
+// Trash
+// @Summary List the automations in the trash
+// @Description Retrieve the soft-deleted automations that were not purged yet, most recently deleted first
+// @Tags Automations
+// @Produce  json
+// @Success 200 {array} models.Automation "Successfully retrieved the trash"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/trash [get]
+func (h *Handler) Trash(c *gin.Context) {
+	automations, err := h.service.FindTrash(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, automations)
+}
+
+// Restore
+// @Summary Restore an automation from the trash
+// @Description Take a soft-deleted automation out of the trash and place it last
+// @Tags Automations
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {object} models.Automation "Successfully restored automation"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/restore [post]
+func (h *Handler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	automation, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, automation)
+}
+
+// Revisions
+// @Summary List the revisions of an automation
+// @Description Retrieve the change history of an automation, newest first
+// @Tags Automations
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {array} models.AutomationRevision "Successfully retrieved revisions"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/revisions [get]
+func (h *Handler) Revisions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	revisions, err := h.service.Revisions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// Revision
+// @Summary Get a revision of an automation
+// @Description Retrieve one revision with its snapshot and field-level diff
+// @Tags Automations
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.AutomationRevision "Successfully retrieved revision"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/revisions/{rev} [get]
+func (h *Handler) Revision(c *gin.Context) {
+	id, number, ok := revisionParams(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.service.Revision(c.Request.Context(), id, number)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// RollbackRevision
+// @Summary Roll an automation back to a revision
+// @Description Update the automation with the fields recorded in a revision; the image and position are kept
+// @Tags Automations
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.Automation "Successfully rolled back automation"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/revisions/{rev}/restore [post]
+func (h *Handler) RollbackRevision(c *gin.Context) {
+	id, number, ok := revisionParams(c)
+	if !ok {
+		return
+	}
+
+	automation, err := h.service.Rollback(c.Request.Context(), id, number)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, automation)
+}
+
+// SetState
+// @Summary Change the lifecycle state of an automation
+// @Description Activate or disable an automation or put it in maintenance; in maintenance the gateway answers with a maintenance page showing the message and ETA
+// @Tags Automations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param state body StateChange true "New state"
+// @Success 200 {object} models.Automation "Successfully changed state"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/state [put]
+func (h *Handler) SetState(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	var change StateChange
+	if err := models.JSON.Unmarshal(body, &change); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	automation, err := h.service.SetState(c.Request.Context(), id, models.Lifecycle{
+		State:        change.State,
+		StateMessage: change.Message,
+		StateETA:     change.ETA,
+	})
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, automation)
+}
+
+// Resolve
+// @Summary Resolve a URL path
+// @Description Find the automation a URL path leads to; for a path the automation had before a rename, redirectTo holds its current path
+// @Tags Automations
+// @Produce  json
+// @Param slug path string true "URL path"
+// @Success 200 {object} Resolution "Successfully resolved URL path"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/resolve/{slug} [get]
+func (h *Handler) Resolve(c *gin.Context) {
+	resolution, err := h.service.Resolve(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resolution)
+}
+
+func revisionParams(c *gin.Context) (uuid.UUID, int, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return uuid.UUID{}, 0, false
+	}
+	number, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return uuid.UUID{}, 0, false
+	}
+	return id, number, true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound), errors.Is(err, ErrRevisionNotFound), errors.Is(err, ErrNotInTrash):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, ErrUnknownCategory), errors.Is(err, ErrInvalidState):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -3,25 +3,54 @@ package automation
 import (
 	"automation-hub-backend/internal/infra"
 	"automation-hub-backend/internal/models"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	WithContext(ctx context.Context) Repository
+	InWorkspace(workspaceID uuid.UUID) Repository
 	FindByID(id uuid.UUID) (*models.Automation, error)
 	Create(automation *models.Automation) (*models.Automation, error)
 	Update(automation *models.Automation) (*models.Automation, error)
 	Delete(id uuid.UUID) error
 	FindAll() ([]*models.Automation, error)
+	Find(filter Filter) ([]*models.Automation, error)
+	FindCategory(id uuid.UUID) (*models.Category, error)
 	MaxPosition() (int, error)
 	GetByURLPath(urlPath string) (*models.Automation, error)
+	GetByName(name string) (*models.Automation, error)
+	FindRedirect(urlPath string) (*models.URLPathRedirect, error)
+	RetireURLPath(automation *models.Automation, oldURLPath string) error
+	FindTrash() ([]*models.Automation, error)
+	FindTrashedByID(id uuid.UUID) (*models.Automation, error)
+	FindTrashedBefore(before time.Time) ([]*models.Automation, error)
+	Restore(automation *models.Automation) (*models.Automation, error)
+	Purge(id uuid.UUID) error
 	Transaction(txFunc func(tx *gorm.DB) error) (err error)
 }
 
+// Filter narrows down a list of automations. An automation matches when it
+// carries every tag, belongs to the category, is in the lifecycle state and
+// its labels satisfy the selector; empty fields match all.
+type Filter struct {
+	Tags     []string
+	Category string
+	State    string
+	Labels   LabelSelector
+}
+
 type GormUserRepository struct {
 	DB *gorm.DB
+	// workspaceID restricts the lookups to one workspace; the zero ID spans
+	// all of them.
+	workspaceID uuid.UUID
 }
 
 func NewGormUserRepository(db *gorm.DB) Repository {
@@ -38,9 +67,42 @@ func DefaultRepository() Repository {
 	return NewGormUserRepository(db)
 }
 
+func (r *GormUserRepository) WithTx(tx *gorm.DB) Repository {
+	return &GormUserRepository{DB: tx, workspaceID: r.workspaceID}
+}
+
+// preloadAssociations loads everything an automation is returned and
+// published with.
+func preloadAssociations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Workspace").Preload("Targets").Preload("HealthCheck").Preload("Health").
+		Preload("Environments", func(db *gorm.DB) *gorm.DB { return db.Order("automation_environments.name asc") }).
+		Preload("Redirects", func(db *gorm.DB) *gorm.DB { return db.Order("url_path_redirects.created_at asc") }).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tags.name asc") }).Preload("Category")
+}
+
+// WithContext returns a repository whose queries run under ctx, so that they
+// are cancelled with it and traced as part of its span.
+func (r *GormUserRepository) WithContext(ctx context.Context) Repository {
+	return &GormUserRepository{DB: r.DB.WithContext(ctx), workspaceID: r.workspaceID}
+}
+
+// InWorkspace returns a repository that only sees the automations of one
+// workspace. Names, paths and positions are unique per workspace, so lookups
+// by them are only meaningful within one.
+func (r *GormUserRepository) InWorkspace(workspaceID uuid.UUID) Repository {
+	return &GormUserRepository{DB: r.DB, workspaceID: workspaceID}
+}
+
+func (r *GormUserRepository) scoped(db *gorm.DB) *gorm.DB {
+	if r.workspaceID == uuid.Nil {
+		return db
+	}
+	return db.Where("automations.workspace_id = ?", r.workspaceID)
+}
+
 func (r *GormUserRepository) FindByID(id uuid.UUID) (*models.Automation, error) {
 	var automation models.Automation
-	err := r.DB.First(&automation, "id = ?", id).Error
+	err := preloadAssociations(r.scoped(r.DB)).First(&automation, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +110,7 @@ func (r *GormUserRepository) FindByID(id uuid.UUID) (*models.Automation, error)
 }
 
 func (r *GormUserRepository) Create(automation *models.Automation) (*models.Automation, error) {
-	err := r.DB.Create(automation).Error
+	err := r.DB.Omit(clause.Associations).Create(automation).Error
 	if err != nil {
 		return nil, err
 	}
@@ -56,15 +118,16 @@ func (r *GormUserRepository) Create(automation *models.Automation) (*models.Auto
 }
 
 func (r *GormUserRepository) Update(automation *models.Automation) (*models.Automation, error) {
-	err := r.DB.Save(automation).Error
+	err := r.DB.Omit(clause.Associations).Save(automation).Error
 	if err != nil {
 		return nil, err
 	}
 	return automation, nil
 }
 
+// Delete moves the automation to the trash; Purge removes it for good.
 func (r *GormUserRepository) Delete(id uuid.UUID) error {
-	err := r.DB.Delete(&models.Automation{}, id).Error
+	err := r.scoped(r.DB).Delete(&models.Automation{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -72,8 +135,37 @@ func (r *GormUserRepository) Delete(id uuid.UUID) error {
 }
 
 func (r *GormUserRepository) FindAll() ([]*models.Automation, error) {
+	return r.Find(Filter{})
+}
+
+// Find returns the automations that match filter, ordered by position.
+func (r *GormUserRepository) Find(filter Filter) ([]*models.Automation, error) {
+	query := preloadAssociations(r.scoped(r.DB))
+	for _, tag := range filter.Tags {
+		query = query.Where("EXISTS (SELECT 1 FROM automation_tags JOIN tags ON tags.id = automation_tags.tag_id "+
+			"WHERE automation_tags.automation_id = automations.id AND tags.name = ?)", tag)
+	}
+	if filter.Category != "" {
+		query = query.Where("category_id IN (SELECT id FROM categories WHERE name = ?)", filter.Category)
+	}
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	for _, requirement := range filter.Labels {
+		switch requirement.Operator {
+		case LabelEquals:
+			query = query.Where("labels ->> ? = ?", requirement.Key, requirement.Value)
+		case LabelNotEquals:
+			query = query.Where("(labels ->> ?) IS DISTINCT FROM ?", requirement.Key, requirement.Value)
+		case LabelExists:
+			query = query.Where("labels ->> ? IS NOT NULL", requirement.Key)
+		case LabelNotExists:
+			query = query.Where("labels ->> ? IS NULL", requirement.Key)
+		}
+	}
+
 	var automations []*models.Automation
-	err := r.DB.Order("position asc").Find(&automations).Error
+	err := query.Order("position asc").Find(&automations).Error
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +195,7 @@ func (r *GormUserRepository) Transaction(txFunc func(tx *gorm.DB) error) (err er
 
 func (r *GormUserRepository) MaxPosition() (int, error) {
 	var automation models.Automation
-	err := r.DB.Order("position desc").First(&automation).Error
+	err := r.scoped(r.DB).Order("position desc").First(&automation).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return 0, nil
@@ -115,9 +207,112 @@ func (r *GormUserRepository) MaxPosition() (int, error) {
 
 func (r *GormUserRepository) GetByURLPath(urlPath string) (*models.Automation, error) {
 	var automation models.Automation
-	err := r.DB.First(&automation, "url_path = ?", urlPath).Error
+	err := r.scoped(r.DB).First(&automation, "url_path = ?", urlPath).Error
+	if err != nil {
+		return nil, err
+	}
+	return &automation, nil
+}
+
+func (r *GormUserRepository) GetByName(name string) (*models.Automation, error) {
+	var automation models.Automation
+	err := r.scoped(r.DB).First(&automation, "name = ?", name).Error
 	if err != nil {
 		return nil, err
 	}
 	return &automation, nil
 }
+
+// FindRedirect finds the automation that had urlPath before a rename.
+func (r *GormUserRepository) FindRedirect(urlPath string) (*models.URLPathRedirect, error) {
+	query := r.DB
+	if r.workspaceID != uuid.Nil {
+		query = query.Where("workspace_id = ?", r.workspaceID)
+	}
+	var redirect models.URLPathRedirect
+	err := query.First(&redirect, "url_path = ?", urlPath).Error
+	if err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// RetireURLPath keeps the path a renamed automation had as a redirect to it,
+// and drops the redirect of the path it has now in case it took back an old
+// one. An empty oldURLPath keeps nothing, for paths that must not redirect.
+// The redirects of automation are reloaded.
+func (r *GormUserRepository) RetireURLPath(automation *models.Automation, oldURLPath string) error {
+	err := r.DB.Where("automation_id = ? AND url_path = ?", automation.ID, automation.URLPath).
+		Delete(&models.URLPathRedirect{}).Error
+	if err != nil {
+		return err
+	}
+	if oldURLPath == "" {
+		return r.DB.Where("automation_id = ?", automation.ID).Order("created_at asc").
+			Find(&automation.Redirects).Error
+	}
+	redirect := &models.URLPathRedirect{
+		WorkspaceID:  automation.WorkspaceID,
+		URLPath:      oldURLPath,
+		AutomationID: automation.ID,
+	}
+	if err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(redirect).Error; err != nil {
+		return err
+	}
+	return r.DB.Where("automation_id = ?", automation.ID).Order("created_at asc").Find(&automation.Redirects).Error
+}
+
+// FindTrash returns the soft-deleted automations, most recently deleted first.
+func (r *GormUserRepository) FindTrash() ([]*models.Automation, error) {
+	var automations []*models.Automation
+	err := preloadAssociations(r.scoped(r.DB.Unscoped())).
+		Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&automations).Error
+	if err != nil {
+		return nil, err
+	}
+	return automations, nil
+}
+
+func (r *GormUserRepository) FindTrashedByID(id uuid.UUID) (*models.Automation, error) {
+	var automation models.Automation
+	err := preloadAssociations(r.scoped(r.DB.Unscoped())).
+		First(&automation, "id = ? AND deleted_at IS NOT NULL", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &automation, nil
+}
+
+func (r *GormUserRepository) FindTrashedBefore(before time.Time) ([]*models.Automation, error) {
+	var automations []*models.Automation
+	err := r.scoped(r.DB.Unscoped()).Where("deleted_at < ?", before).Find(&automations).Error
+	if err != nil {
+		return nil, err
+	}
+	return automations, nil
+}
+
+// Restore takes the automation out of the trash and saves its other fields.
+func (r *GormUserRepository) Restore(automation *models.Automation) (*models.Automation, error) {
+	automation.DeletedAt = gorm.DeletedAt{}
+	err := r.DB.Unscoped().Omit(clause.Associations).Save(automation).Error
+	if err != nil {
+		return nil, err
+	}
+	return automation, nil
+}
+
+// Purge removes the row for good, together with its targets, health check,
+// redirects, uptime history and notification deliveries.
+func (r *GormUserRepository) Purge(id uuid.UUID) error {
+	return r.scoped(r.DB.Unscoped()).Delete(&models.Automation{}, id).Error
+}
+
+func (r *GormUserRepository) FindCategory(id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.DB.First(&category, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
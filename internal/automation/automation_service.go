@@ -3,8 +3,14 @@ package automation
 import (
 	"automation-hub-backend/internal/config"
 	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/metrics"
 	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"automation-hub-backend/internal/revision"
+	"automation-hub-backend/internal/tracing"
 	"automation-hub-backend/internal/util"
+	"automation-hub-backend/internal/workspace"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -17,57 +23,118 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Service interface {
-	FindByID(id uuid.UUID) (*models.Automation, error)
-	Create(automation *models.Automation) (*models.Automation, error)
-	Update(automation *models.Automation) (*models.Automation, error)
-	Delete(id uuid.UUID) error
-	FindAll() ([]*models.Automation, error)
-	SwapOrder(id1 uuid.UUID, id2 uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Automation, error)
+	Create(ctx context.Context, automation *models.Automation) (*models.Automation, error)
+	Update(ctx context.Context, automation *models.Automation) (*models.Automation, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context, filter Filter) ([]*models.Automation, error)
+	SwapOrder(ctx context.Context, id1 uuid.UUID, id2 uuid.UUID) error
+	FindTrash(ctx context.Context) ([]*models.Automation, error)
+	Restore(ctx context.Context, id uuid.UUID) (*models.Automation, error)
+	Revisions(ctx context.Context, id uuid.UUID) ([]*models.AutomationRevision, error)
+	Revision(ctx context.Context, id uuid.UUID, number int) (*models.AutomationRevision, error)
+	Rollback(ctx context.Context, id uuid.UUID, number int) (*models.Automation, error)
+	SetState(ctx context.Context, id uuid.UUID, lifecycle models.Lifecycle) (*models.Automation, error)
+	Resolve(ctx context.Context, urlPath string) (*Resolution, error)
 }
 
+// Resolution is the automation a URL path leads to. RedirectTo is the
+// current path of the automation when urlPath is one it had before a rename.
+type Resolution struct {
+	Automation *models.Automation `json:"automation"`
+	RedirectTo string             `json:"redirectTo,omitempty"`
+}
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrNotInTrash         = errors.New("automation is not in the trash")
+	ErrNameTaken          = errors.New("an automation with this name already exists")
+	ErrRevisionNotFound   = errors.New("revision not found")
+	ErrUnknownCategory    = errors.New("category does not exist")
+	ErrNoWorkspace        = errors.New("no workspace given")
+	ErrInvalidState       = errors.New("invalid state")
+)
+
 type service struct {
-	repo      Repository
-	publisher events.Publisher
+	repo       Repository
+	outbox     outbox.Repository
+	revisions  revision.Repository
+	workspaces workspace.Repository
 }
 
-func NewService(repo Repository, publisher events.Publisher) Service {
+func NewService(repo Repository, outboxRepo outbox.Repository, revisionRepo revision.Repository,
+	workspaceRepo workspace.Repository) Service {
 	return &service{
-		repo:      repo,
-		publisher: publisher,
+		repo:       repo,
+		outbox:     outboxRepo,
+		revisions:  revisionRepo,
+		workspaces: workspaceRepo,
 	}
 }
 
 func DefaultService() Service {
 	repo := DefaultRepository()
-	pub := events.DefaultPublisher()
-	return NewService(repo, *pub)
+	outboxRepo := outbox.DefaultRepository()
+	revisionRepo := revision.DefaultRepository()
+	workspaceRepo := workspace.DefaultRepository()
+	return NewService(repo, outboxRepo, revisionRepo, workspaceRepo)
 }
 
-func (s *service) FindByID(id uuid.UUID) (*models.Automation, error) {
-	return s.repo.FindByID(id)
+func (s *service) FindByID(ctx context.Context, id uuid.UUID) (_ *models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.FindByID")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindByID(id)
 }
 
-func (s *service) Create(automation *models.Automation) (*models.Automation, error) {
+func (s *service) Create(ctx context.Context, automation *models.Automation) (_ *models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Create")
+	defer func() { tracing.End(span, err) }()
+	repo, ws, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	automation.ID = uuid.UUID{} // reset ID
+	automation.WorkspaceID = ws.ID
+	automation.Workspace = ws
+	// targets, health checks and environments are managed through their own
+	// endpoints
+	automation.Targets = nil
+	automation.Environments = nil
+	automation.Redirects = nil
+	automation.HealthCheck = nil
+	automation.Health = nil
+	automation.Tags = nil
+	automation.Lifecycle = models.Lifecycle{State: models.StateActive}
+	automation.DeletedAt = gorm.DeletedAt{}
 
 	if automation.ImageFile != nil {
-		newFileName, err := s.processImageFile(automation.ImageFile)
+		newFileName, err := s.processImageFile(ctx, automation.ImageFile)
 		if err != nil {
 			return nil, err
 		}
 		automation.Image = newFileName
 	}
 
-	maxPosition, err := s.repo.MaxPosition()
+	if err := s.resolveCategory(repo, automation); err != nil {
+		return nil, err
+	}
+
+	maxPosition, err := repo.MaxPosition()
 	if err != nil {
 		return nil, err
 	}
 	automation.Position = maxPosition + 1
 
-	err = s.ensureUniqueURLPath(automation)
+	err = s.ensureUniqueURLPath(ctx, automation)
 	if err != nil {
 		return nil, err
 	}
@@ -76,42 +143,74 @@ func (s *service) Create(automation *models.Automation) (*models.Automation, err
 		return nil, err
 	}
 
-	automationCreated, err := s.repo.Create(automation)
+	var automationCreated *models.Automation
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		created, errCreate := repo.WithTx(tx).Create(automation)
+		if errCreate != nil {
+			return errCreate
+		}
+		automationCreated = created
+
+		if errRecord := s.revisions.WithTx(tx).Record(revision.New(ctx, models.RevisionCreate, nil,
+			automationCreated)); errRecord != nil {
+			return errRecord
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.CreateEvent, automationCreated))
+	})
 	if err != nil {
 		return nil, err
 	}
-	event := &events.AutomationEvent{
-		Type:       events.CreateEvent,
-		Automation: automationCreated,
-	}
-	err = s.publisher.Publish(event)
+	return automationCreated, nil
+}
+
+func (s *service) Update(ctx context.Context, automation *models.Automation) (_ *models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Update")
+	defer func() { tracing.End(span, err) }()
+
+	return s.update(ctx, automation, models.RevisionUpdate, 0)
+}
+
+// update saves automation and records the change as a revision with the
+// given action. rolledBackTo names the revision a rollback restored.
+func (s *service) update(ctx context.Context, automation *models.Automation, action string,
+	rolledBackTo int) (*models.Automation, error) {
+	repo, ws, err := s.scoped(ctx)
 	if err != nil {
-		log.Printf("Failed to publish create event to Kafka: %v", err)
 		return nil, err
 	}
-	return automationCreated, nil
-}
 
-func (s *service) Update(automation *models.Automation) (*models.Automation, error) {
-	currentAutomation, err := s.repo.FindByID(automation.ID)
+	currentAutomation, err := repo.FindByID(automation.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	automation.WorkspaceID = currentAutomation.WorkspaceID
+	automation.Workspace = currentAutomation.Workspace
 	automation.Position = currentAutomation.Position
+	automation.Targets = currentAutomation.Targets
+	automation.Environments = currentAutomation.Environments
+	automation.Redirects = currentAutomation.Redirects
+	automation.HealthCheck = currentAutomation.HealthCheck
+	automation.Health = currentAutomation.Health
+	automation.Tags = currentAutomation.Tags
+	automation.Lifecycle = currentAutomation.Lifecycle
+	automation.DeletedAt = currentAutomation.DeletedAt
+	if err := s.resolveCategory(repo, automation); err != nil {
+		return nil, err
+	}
 
 	if automation.ImageFile != nil {
-		newFileName, errIf := s.processImageFile(automation.ImageFile)
+		newFileName, errIf := s.processImageFile(ctx, automation.ImageFile)
 		log.Printf("Image processed and saved as: %s", newFileName)
 		if errIf != nil {
 			return nil, errIf
 		}
-		if ok := s.deleteImage(currentAutomation.Image); ok != nil {
+		if ok := deleteImage(currentAutomation.Image); ok != nil {
 			return nil, ok
 		}
 		automation.Image = newFileName
 	} else if automation.RemoveImage {
-		if noDeleted := s.deleteImage(currentAutomation.Image); noDeleted != nil {
+		if noDeleted := deleteImage(currentAutomation.Image); noDeleted != nil {
 			return nil, noDeleted
 		}
 		automation.Image = ""
@@ -121,7 +220,7 @@ func (s *service) Update(automation *models.Automation) (*models.Automation, err
 	var oldUrlPath string
 	if currentAutomation.Name != automation.Name {
 		oldUrlPath = currentAutomation.URLPath
-		err = s.ensureUniqueURLPath(automation)
+		err = s.ensureUniqueURLPath(ctx, automation)
 		if err != nil {
 			return nil, err
 		}
@@ -134,59 +233,95 @@ func (s *service) Update(automation *models.Automation) (*models.Automation, err
 		return nil, errValidate
 	}
 
-	automationUpdated, err := s.repo.Update(automation)
-	automationUpdated.OldUrlPath = oldUrlPath
-
-	event := &events.AutomationEvent{
-		Type:       events.UpdateEvent,
-		Automation: automationUpdated,
-	}
+	var automationUpdated *models.Automation
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		updated, errUpdate := repo.WithTx(tx).Update(automation)
+		if errUpdate != nil {
+			return errUpdate
+		}
+		automationUpdated = updated
+		automationUpdated.OldUrlPath = oldUrlPath
+		if oldUrlPath != automationUpdated.URLPath {
+			retired := oldUrlPath
+			reserved, errReserved := s.prefixReserved(ws, oldUrlPath)
+			if errReserved != nil {
+				return errReserved
+			}
+			if reserved {
+				log.Printf("Not keeping %q as a redirect to automation %s, it is now a gateway path prefix",
+					oldUrlPath, automationUpdated.ID)
+				retired = ""
+			}
+			if errRetire := repo.WithTx(tx).RetireURLPath(automationUpdated, retired); errRetire != nil {
+				return errRetire
+			}
+		}
 
-	err = s.publisher.Publish(event)
+		updateRevision := revision.New(ctx, action, currentAutomation, automationUpdated)
+		updateRevision.RolledBackTo = rolledBackTo
+		if errRecord := s.revisions.WithTx(tx).Record(updateRevision); errRecord != nil {
+			return errRecord
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, automationUpdated))
+	})
 	if err != nil {
-		log.Printf("Failed to publish update event to Kafka: %v", err)
 		return nil, err
 	}
 
 	return automationUpdated, nil
 }
 
-func (s *service) Delete(id uuid.UUID) error {
-	automation, err := s.repo.FindByID(id)
+func (s *service) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "automation.Delete")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.repo.Delete(id)
+	automation, err := repo.FindByID(id)
 	if err != nil {
 		return err
 	}
 
-	event := &events.AutomationEvent{
-		Type:       events.DeleteEvent,
-		Automation: automation,
-	}
+	return repo.Transaction(func(tx *gorm.DB) error {
+		if errDelete := repo.WithTx(tx).Delete(id); errDelete != nil {
+			return errDelete
+		}
+		if errRecord := s.revisions.WithTx(tx).Record(revision.New(ctx, models.RevisionDelete, automation,
+			automation)); errRecord != nil {
+			return errRecord
+		}
+
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.DeleteEvent, automation))
+	})
+}
 
-	err = s.publisher.Publish(event)
+func (s *service) FindAll(ctx context.Context, filter Filter) (_ []*models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.FindAll")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
 	if err != nil {
-		log.Printf("Failed to publish delete event to Kafka: %v", err)
-		return err
+		return nil, err
 	}
-
-	return nil
+	return repo.Find(filter)
 }
 
-func (s *service) FindAll() ([]*models.Automation, error) {
-	return s.repo.FindAll()
-}
+func (s *service) SwapOrder(ctx context.Context, id1 uuid.UUID, id2 uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "automation.SwapOrder")
+	defer func() { tracing.End(span, err) }()
+	repo, ws, err := s.scoped(ctx)
+	if err != nil {
+		return err
+	}
 
-func (s *service) SwapOrder(id1 uuid.UUID, id2 uuid.UUID) error {
-	return s.repo.Transaction(func(tx *gorm.DB) error {
-		automation1, err := s.repo.FindByID(id1)
+	return repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := repo.WithTx(tx)
+		automation1, err := txRepo.FindByID(id1)
 		if err != nil {
 			return err
 		}
-		automation2, err := s.repo.FindByID(id2)
+		automation2, err := txRepo.FindByID(id2)
 		if err != nil {
 			return err
 		}
@@ -194,34 +329,317 @@ func (s *service) SwapOrder(id1 uuid.UUID, id2 uuid.UUID) error {
 		pos1 := automation1.Position
 		pos2 := automation2.Position
 
-		maxPosition, err := s.repo.MaxPosition()
+		maxPosition, err := txRepo.MaxPosition()
 		if err != nil {
 			return err
 		}
 		tempPosition := maxPosition + 1
 
 		automation1.Position = tempPosition
-		if err := tx.Save(automation1).Error; err != nil {
+		if _, err := txRepo.Update(automation1); err != nil {
 			return err
 		}
 
 		automation2.Position = pos1
-		if err := tx.Save(automation2).Error; err != nil {
+		if _, err := txRepo.Update(automation2); err != nil {
 			return err
 		}
 
 		automation1.Position = pos2
-		if err := tx.Save(automation1).Error; err != nil {
+		if _, err := txRepo.Update(automation1); err != nil {
 			return err
 		}
 
-		return nil
+		positions, err := s.positions(txRepo)
+		if err != nil {
+			return err
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewReorderEvent(ctx, ws.Slug, positions))
 	})
 }
 
-func (s *service) processImageFile(file *multipart.FileHeader) (string, error) {
+// FindTrash returns the soft-deleted automations that were not purged yet.
+func (s *service) FindTrash(ctx context.Context) (_ []*models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.FindTrash")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindTrash()
+}
+
+// Restore takes an automation out of the trash. It is placed last, keeps its
+// path unless another automation, environment or workspace took it
+// meanwhile, and is announced to consumers as newly created since they
+// dropped it on delete.
+func (s *service) Restore(ctx context.Context, id uuid.UUID) (_ *models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Restore")
+	defer func() { tracing.End(span, err) }()
+	repo, ws, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	automation, err := repo.FindTrashedByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+
+	existing, err := repo.GetByName(automation.Name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrNameTaken
+	}
+
+	maxPosition, err := repo.MaxPosition()
+	if err != nil {
+		return nil, err
+	}
+	automation.Position = maxPosition + 1
+
+	trashed := *automation
+	taken, err := s.urlPathTaken(repo, ws, automation.URLPath, automation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		if err := s.ensureUniqueURLPath(ctx, automation); err != nil {
+			return nil, err
+		}
+	}
+
+	var automationRestored *models.Automation
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		restored, errRestore := repo.WithTx(tx).Restore(automation)
+		if errRestore != nil {
+			return errRestore
+		}
+		automationRestored = restored
+
+		if errRecord := s.revisions.WithTx(tx).Record(revision.New(ctx, models.RevisionRestore, &trashed,
+			automationRestored)); errRecord != nil {
+			return errRecord
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.CreateEvent, automationRestored))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return automationRestored, nil
+}
+
+// Revisions returns the history of an automation, newest first. The history
+// of an automation in the trash stays readable until it is purged.
+func (s *service) Revisions(ctx context.Context, id uuid.UUID) (_ []*models.AutomationRevision, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Revisions")
+	defer func() { tracing.End(span, err) }()
+
+	revisions, err := s.revisions.WithContext(ctx).FindByAutomation(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		if _, err := s.findAny(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return revisions, nil
+}
+
+func (s *service) Revision(ctx context.Context, id uuid.UUID, number int) (_ *models.AutomationRevision, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Revision")
+	defer func() { tracing.End(span, err) }()
+
+	found, err := s.revisions.WithContext(ctx).Find(id, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
+
+// Rollback puts the fields recorded in a revision back through the regular
+// update path, so the URL path is regenerated, consumers get an update event
+// and the rollback itself becomes the newest revision. The image and the
+// position are kept as they are.
+func (s *service) Rollback(ctx context.Context, id uuid.UUID, number int) (_ *models.Automation, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Rollback")
+	defer func() { tracing.End(span, err) }()
+
+	target, err := s.Revision(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+
+	automation := *current
+	target.Snapshot.Apply(&automation)
+	return s.update(ctx, &automation, models.RevisionRollback, target.Revision)
+}
+
+// SetState moves an automation into a lifecycle state. The change is
+// announced with the event type of the new state rather than as an update,
+// and recorded as a state revision.
+func (s *service) SetState(ctx context.Context, id uuid.UUID, lifecycle models.Lifecycle) (_ *models.Automation,
+	err error) {
+	ctx, span := tracing.Start(ctx, "automation.SetState")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lifecycle.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	automation, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+
+	current := *automation
+	changedAt := time.Now().UTC()
+	lifecycle.StateChangedAt = &changedAt
+	automation.Lifecycle = lifecycle
+
+	var automationUpdated *models.Automation
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		updated, errUpdate := repo.WithTx(tx).Update(automation)
+		if errUpdate != nil {
+			return errUpdate
+		}
+		automationUpdated = updated
+
+		if errRecord := s.revisions.WithTx(tx).Record(revision.New(ctx, models.RevisionState, &current,
+			automationUpdated)); errRecord != nil {
+			return errRecord
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.StateEventType(lifecycle.State),
+			automationUpdated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return automationUpdated, nil
+}
+
+// Resolve finds the automation that has urlPath, or had it before a rename.
+func (s *service) Resolve(ctx context.Context, urlPath string) (_ *Resolution, err error) {
+	ctx, span := tracing.Start(ctx, "automation.Resolve")
+	defer func() { tracing.End(span, err) }()
+	repo, _, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := repo.GetByURLPath(urlPath)
+	if err == nil {
+		current, err = repo.FindByID(current.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &Resolution{Automation: current}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	redirect, err := repo.FindRedirect(urlPath)
+	if err == nil {
+		current, err = repo.FindByID(redirect.AutomationID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAutomationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Resolution{Automation: current, RedirectTo: current.URLPath}, nil
+}
+
+// findAny finds an automation whether or not it is in the trash.
+func (s *service) findAny(ctx context.Context, id uuid.UUID) (*models.Automation, error) {
+	repo, _, err := s.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	automation, err := repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		automation, err = repo.FindTrashedByID(id)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAutomationNotFound
+	}
+	return automation, err
+}
+
+// scoped returns the repository of the workspace the request operates in,
+// together with that workspace.
+func (s *service) scoped(ctx context.Context) (Repository, *models.Workspace, error) {
+	ws := workspace.FromContext(ctx)
+	if ws == nil {
+		return nil, nil, ErrNoWorkspace
+	}
+	return s.repo.WithContext(ctx).InWorkspace(ws.ID), ws, nil
+}
+
+// resolveCategory checks that the category of the automation exists and
+// attaches it, so responses and events carry the category itself.
+func (s *service) resolveCategory(repo Repository, automation *models.Automation) error {
+	automation.Category = nil
+	if automation.CategoryID == nil {
+		return nil
+	}
+	category, err := repo.FindCategory(*automation.CategoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownCategory
+		}
+		return err
+	}
+	automation.Category = category
+	return nil
+}
+
+func (s *service) positions(repo Repository) (map[uuid.UUID]int, error) {
+	automations, err := repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[uuid.UUID]int, len(automations))
+	for _, automation := range automations {
+		positions[automation.ID] = automation.Position
+	}
+	return positions, nil
+}
+
+func (s *service) processImageFile(ctx context.Context, file *multipart.FileHeader) (_ string, err error) {
+	_, span := tracing.Start(ctx, "automation.processImageFile")
+	defer func() { tracing.End(span, err) }()
+
 	log.Println("Starting processImageFile function")
 	if file.Size > config.AppConfig.ImageMaxSize {
+		metrics.RejectImage("too_large")
 		return "", fmt.Errorf("image is too large (%d). Max size is %d Mb", file.Size, config.AppConfig.ImageMaxSize)
 	}
 
@@ -229,6 +647,7 @@ func (s *service) processImageFile(file *multipart.FileHeader) (string, error) {
 	fmt.Printf("Filename: %s, Extracted Extension: %s\n", file.Filename, ext)
 
 	if !contains(config.AppConfig.ImageExtensions, ext) {
+		metrics.RejectImage("extension")
 		return "", fmt.Errorf("invalid image extension. Allowed extensions are: %v", config.AppConfig.ImageExtensions)
 	}
 
@@ -250,10 +669,12 @@ func (s *service) processImageFile(file *multipart.FileHeader) (string, error) {
 
 	fileType := http.DetectContentType(buffer)
 	if !strings.HasPrefix(fileType, "image/") {
+		metrics.RejectImage("not_image")
 		return "", fmt.Errorf("file is not an image")
 	}
 	mimeSuffix := strings.TrimPrefix(fileType, "image/")
 	if !contains(config.AppConfig.ImageExtensions, "."+mimeSuffix) {
+		metrics.RejectImage("mime_mismatch")
 		return "", fmt.Errorf("mismatch between file extension and MIME type")
 	}
 
@@ -293,10 +714,11 @@ func (s *service) processImageFile(file *multipart.FileHeader) (string, error) {
 		return "", err
 	}
 	log.Printf("Copied %d bytes to %s", n, dst.Name())
+	metrics.ObserveImageUpload(n)
 	return newFileName, nil
 }
 
-func (s *service) deleteImage(imageName string) error {
+func deleteImage(imageName string) error {
 	if imageName == "" {
 		return nil
 	}
@@ -317,20 +739,24 @@ func contains(slice []string, str string) bool {
 	return false
 }
 
-func (s *service) ensureUniqueURLPath(automation *models.Automation) error {
+func (s *service) ensureUniqueURLPath(ctx context.Context, automation *models.Automation) (err error) {
+	ctx, span := tracing.Start(ctx, "automation.ensureUniqueURLPath")
+	defer func() { tracing.End(span, err) }()
+	repo, ws, err := s.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
 	baseURLPath := util.GenerateURLPath(automation.Name)
 	uniqueURLPath := baseURLPath
 	counter := 0
 
 	for {
-		existingAutomation, err := s.repo.GetByURLPath(uniqueURLPath)
+		taken, err := s.urlPathTaken(repo, ws, uniqueURLPath, automation.ID)
 		if err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return err
-			}
+			return err
 		}
-
-		if existingAutomation == nil || existingAutomation.ID == automation.ID {
+		if !taken {
 			break
 		}
 
@@ -339,5 +765,55 @@ func (s *service) ensureUniqueURLPath(automation *models.Automation) error {
 	}
 
 	automation.URLPath = uniqueURLPath
+	automation.SetEnvironmentPaths()
 	return nil
 }
+
+// urlPathTaken reports whether another automation has urlPath, or had it
+// before a rename and keeps it reserved for redirects, or whether the path
+// is reserved as a gateway path prefix.
+func (s *service) urlPathTaken(repo Repository, ws *models.Workspace, urlPath string, id uuid.UUID) (bool,
+	error) {
+	taken, err := urlPathTakenInWorkspace(repo, urlPath, id)
+	if err != nil || taken {
+		return taken, err
+	}
+	return s.prefixReserved(ws, urlPath)
+}
+
+// prefixReserved reports whether urlPath is the name of an environment or
+// the slug of a workspace. Automations of the default workspace are served
+// at the top level, so their paths, current and retired, share the first
+// segment of gateway paths with those and must not take them.
+func (s *service) prefixReserved(ws *models.Workspace, urlPath string) (bool, error) {
+	if !ws.IsDefault() {
+		return false, nil
+	}
+	reserved, err := s.workspaces.IsEnvironmentName(urlPath)
+	if err != nil || reserved {
+		return reserved, err
+	}
+	_, err = s.workspaces.FindBySlug(urlPath)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// urlPathTakenInWorkspace reports whether another automation of the
+// workspace of repo has urlPath, or had it before a rename.
+func urlPathTakenInWorkspace(repo Repository, urlPath string, id uuid.UUID) (bool, error) {
+	existing, err := repo.GetByURLPath(urlPath)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if existing != nil && existing.ID != id {
+		return true, nil
+	}
+
+	redirect, err := repo.FindRedirect(urlPath)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	return redirect != nil && redirect.AutomationID != id, nil
+}
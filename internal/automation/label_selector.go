@@ -0,0 +1,64 @@
+package automation
+
+import (
+	"automation-hub-backend/internal/models"
+	"fmt"
+	"strings"
+)
+
+// Label selector operators.
+const (
+	LabelEquals    = "="
+	LabelNotEquals = "!="
+	LabelExists    = "exists"
+	LabelNotExists = "!exists"
+)
+
+// LabelRequirement is one comma-separated term of a label selector.
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+// LabelSelector matches automations whose labels meet every requirement.
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses selectors such as "env=prod,tier!=batch,canary"
+// in the style of Kubernetes equality-based selectors: "key=value" (or
+// "key==value"), "key!=value", which also matches automations without the
+// label, "key" for presence and "!key" for absence.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var requirements LabelSelector
+	if strings.TrimSpace(selector) == "" {
+		return requirements, nil
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var requirement LabelRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			requirement = LabelRequirement{Key: key, Operator: LabelNotEquals, Value: value}
+		case strings.Contains(term, "=="):
+			key, value, _ := strings.Cut(term, "==")
+			requirement = LabelRequirement{Key: key, Operator: LabelEquals, Value: value}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			requirement = LabelRequirement{Key: key, Operator: LabelEquals, Value: value}
+		case strings.HasPrefix(term, "!"):
+			requirement = LabelRequirement{Key: strings.TrimPrefix(term, "!"), Operator: LabelNotExists}
+		default:
+			requirement = LabelRequirement{Key: term, Operator: LabelExists}
+		}
+
+		requirement.Key = strings.TrimSpace(requirement.Key)
+		requirement.Value = strings.TrimSpace(requirement.Value)
+		if err := models.ValidateLabels(map[string]string{requirement.Key: requirement.Value}); err != nil {
+			return nil, fmt.Errorf("invalid label selector term %q: %w", term, err)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
@@ -0,0 +1,83 @@
+package automation
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/revision"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// purgeInterval is how often the trash is checked for expired automations.
+const purgeInterval = time.Hour
+
+// PurgeJob removes the automations that stayed in the trash longer than the
+// retention, together with their images and revision history. Purging twice
+// is harmless, so replicas may run it concurrently.
+type PurgeJob struct {
+	repo      Repository
+	revisions revision.Repository
+	retention time.Duration
+}
+
+func NewPurgeJob(repo Repository, revisionRepo revision.Repository, retention time.Duration) *PurgeJob {
+	return &PurgeJob{
+		repo:      repo,
+		revisions: revisionRepo,
+		retention: retention,
+	}
+}
+
+func DefaultPurgeJob() *PurgeJob {
+	return NewPurgeJob(DefaultRepository(), revision.DefaultRepository(), config.AppConfig.TrashRetention)
+}
+
+func (j *PurgeJob) Run(ctx context.Context) {
+	if j.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		j.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PurgeJob) purge(ctx context.Context) {
+	repo := j.repo.WithContext(ctx)
+	automations, err := repo.FindTrashedBefore(time.Now().UTC().Add(-j.retention))
+	if err != nil {
+		log.Printf("Failed to find expired automations in the trash: %v", err)
+		return
+	}
+
+	purged := 0
+	for _, automation := range automations {
+		err := repo.Transaction(func(tx *gorm.DB) error {
+			if err := repo.WithTx(tx).Purge(automation.ID); err != nil {
+				return err
+			}
+			return j.revisions.WithTx(tx).DeleteByAutomation(automation.ID)
+		})
+		if err != nil {
+			log.Printf("Failed to purge automation %s: %v", automation.ID, err)
+			continue
+		}
+		// the row is gone, so a leftover image is only wasted space
+		if err := deleteImage(automation.Image); err != nil {
+			log.Printf("Failed to delete image %s of purged automation %s: %v", automation.Image, automation.ID, err)
+		}
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("Purged %d automations from the trash", purged)
+	}
+}
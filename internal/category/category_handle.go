@@ -0,0 +1,165 @@
+package category
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// GetAll
+// @Summary Get all categories
+// @Description Retrieve every category, ordered by name
+// @Tags Categories
+// @Produce  json
+// @Success 200 {array} models.Category "Successfully retrieved categories"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /categories [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	categories, err := h.service.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// Create
+// @Summary Create a category
+// @Description Create a category; names are unique
+// @Tags Categories
+// @Accept  json
+// @Produce  json
+// @Param category body models.Category true "Category data"
+// @Success 201 {object} models.Category "Successfully created category"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /categories [post]
+func (h *Handler) Create(c *gin.Context) {
+	var category models.Category
+	if !readBody(c, &category) {
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), &category)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Update
+// @Summary Update a category
+// @Description Update a category; the automations in it are republished
+// @Tags Categories
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Param category body models.Category true "Category data"
+// @Success 200 {object} models.Category "Successfully updated category"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /categories/{id} [patch]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var category models.Category
+	if !readBody(c, &category) {
+		return
+	}
+	category.ID = id
+
+	updated, err := h.service.Update(c.Request.Context(), &category)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete
+// @Summary Delete a category
+// @Description Delete a category; its automations are left without one
+// @Tags Categories
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Success 204 "Successfully deleted category"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /categories/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for " + param})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readBody(c *gin.Context, value interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidCategory):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCategoryExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
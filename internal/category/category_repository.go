@@ -0,0 +1,94 @@
+package category
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindAll() ([]*models.Category, error)
+	FindByID(id uuid.UUID) (*models.Category, error)
+	FindByName(name string) (*models.Category, error)
+	Create(category *models.Category) (*models.Category, error)
+	Update(category *models.Category) (*models.Category, error)
+	Delete(id uuid.UUID) error
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindAll() ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.DB.Order("name asc").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *GormRepository) FindByID(id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.DB.First(&category, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *GormRepository) FindByName(name string) (*models.Category, error) {
+	var category models.Category
+	err := r.DB.First(&category, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *GormRepository) Create(category *models.Category) (*models.Category, error) {
+	err := r.DB.Create(category).Error
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (r *GormRepository) Update(category *models.Category) (*models.Category, error) {
+	err := r.DB.Save(category).Error
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete leaves the automations of the category, including those in the
+// trash, without a category and then removes the category.
+func (r *GormRepository) Delete(id uuid.UUID) error {
+	err := r.DB.Model(&models.Automation{}).Unscoped().Where("category_id = ?", id).
+		Update("category_id", nil).Error
+	if err != nil {
+		return err
+	}
+	return r.DB.Delete(&models.Category{}, id).Error
+}
@@ -0,0 +1,165 @@
+package category
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with this name already exists")
+	ErrInvalidCategory  = errors.New("invalid category")
+)
+
+type Service interface {
+	FindAll(ctx context.Context) ([]*models.Category, error)
+	Create(ctx context.Context, category *models.Category) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category) (*models.Category, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+	outbox         outbox.Repository
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+		outbox:         outboxRepo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context) ([]*models.Category, error) {
+	return s.repo.FindAll()
+}
+
+func (s *service) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	category.ID = uuid.UUID{} // reset ID
+	if err := category.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCategory, err)
+	}
+
+	var created *models.Category
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if err := ensureNameFree(txRepo, category); err != nil {
+			return err
+		}
+		var err error
+		created, err = txRepo.Create(category)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update changes a category. The automations in it are republished so that
+// consumers see the change.
+func (s *service) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	if err := category.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCategory, err)
+	}
+
+	var updated *models.Category
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		current, err := findCategory(txRepo, category.ID)
+		if err != nil {
+			return err
+		}
+		if err := ensureNameFree(txRepo, category); err != nil {
+			return err
+		}
+		members, err := s.automationRepo.WithTx(tx).Find(automation.Filter{Category: current.Name})
+		if err != nil {
+			return err
+		}
+
+		updated, err = txRepo.Update(category)
+		if err != nil {
+			return err
+		}
+		return s.publishChanges(ctx, tx, members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes a category; its automations are left without one and
+// republished.
+func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		current, err := findCategory(txRepo, id)
+		if err != nil {
+			return err
+		}
+		members, err := s.automationRepo.WithTx(tx).Find(automation.Filter{Category: current.Name})
+		if err != nil {
+			return err
+		}
+
+		if err := txRepo.Delete(id); err != nil {
+			return err
+		}
+		return s.publishChanges(ctx, tx, members)
+	})
+}
+
+// publishChanges queues an update event for every automation, reloaded so the
+// events carry their category as it is now.
+func (s *service) publishChanges(ctx context.Context, tx *gorm.DB, automations []*models.Automation) error {
+	for _, changed := range automations {
+		current, err := s.automationRepo.WithTx(tx).FindByID(changed.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, current)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureNameFree(repo Repository, category *models.Category) error {
+	existing, err := repo.FindByName(category.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != category.ID {
+		return ErrCategoryExists
+	}
+	return nil
+}
+
+func findCategory(repo Repository, id uuid.UUID) (*models.Category, error) {
+	category, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
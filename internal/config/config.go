@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,6 +14,13 @@ const (
 	baseUrl          string = "BASE_URL"
 	nginxContainer   string = "NGINX_CONTAINER"
 	configDir        string = "CONFIG_DIR"
+	gatewayEnabled   string = "GATEWAY_ENABLED"
+	gatewayTemplate  string = "GATEWAY_TEMPLATE"
+	gatewayReload    string = "GATEWAY_RELOAD_COMMAND"
+	gatewayValidate  string = "GATEWAY_VALIDATE_COMMAND"
+	maintenanceURL   string = "GATEWAY_MAINTENANCE_URL"
+	gatewayPoll      string = "GATEWAY_POLL_INTERVAL"
+	gatewayResync    string = "GATEWAY_RESYNC_INTERVAL"
 	dbHost           string = "DB_HOST"
 	dbPort           string = "DB_PORT"
 	dbName           string = "DB_NAME"
@@ -23,6 +31,36 @@ const (
 	imageSaveDir     string = "IMAGE_SAVE_DIR"
 	kafkaBrokers     string = "KAFKA_BROKERS"
 	kafkaTopic       string = "KAFKA_TOPIC"
+	compactedTopic   string = "KAFKA_COMPACTED_TOPIC"
+	workspaceTopics  string = "KAFKA_WORKSPACE_TOPICS"
+	snapshotInterval string = "SNAPSHOT_INTERVAL"
+	eventTransport   string = "EVENT_TRANSPORT"
+	eventSource      string = "EVENT_SOURCE"
+	eventEncoding    string = "EVENT_ENCODING"
+	memoryBufferSize string = "MEMORY_BUFFER_SIZE"
+	pgNotifyChannel  string = "PG_NOTIFY_CHANNEL"
+	natsURL          string = "NATS_URL"
+	natsSubject      string = "NATS_SUBJECT"
+	eventFilePath    string = "EVENT_FILE_PATH"
+	outboxPoll       string = "OUTBOX_POLL_INTERVAL"
+	outboxBatchSize  string = "OUTBOX_BATCH_SIZE"
+	outboxMaxBackoff string = "OUTBOX_MAX_BACKOFF"
+	outboxRetention  string = "OUTBOX_RETENTION"
+	healthEnabled    string = "HEALTHCHECK_ENABLED"
+	healthWorkers    string = "HEALTHCHECK_WORKERS"
+	uptimeSamples    string = "UPTIME_SAMPLE_RETENTION"
+	uptimeRollups    string = "UPTIME_ROLLUP_RETENTION"
+	notifyPoll       string = "NOTIFY_POLL_INTERVAL"
+	notifyAttempts   string = "NOTIFY_MAX_ATTEMPTS"
+	notifyTimeout    string = "NOTIFY_TIMEOUT"
+	notifyRetention  string = "NOTIFY_RETENTION"
+	tracingExporter  string = "TRACING_EXPORTER"
+	tracingEndpoint  string = "TRACING_ENDPOINT"
+	tracingInsecure  string = "TRACING_INSECURE"
+	tracingRatio     string = "TRACING_SAMPLE_RATIO"
+	tracingService   string = "TRACING_SERVICE_NAME"
+	trashRetention   string = "TRASH_RETENTION"
+	maintenancePoll  string = "MAINTENANCE_POLL_INTERVAL"
 )
 
 type Configuration struct {
@@ -40,6 +78,60 @@ type Configuration struct {
 	ImageSaveDir    string
 	Brokers         []string
 	Topic           string
+	CompactedTopic  string
+
+	KafkaWorkspaceTopics bool
+
+	SnapshotInterval time.Duration
+
+	EventTransport   string
+	EventSource      string
+	EventEncoding    string
+	MemoryBufferSize int
+	PgNotifyChannel  string
+	NatsURL          string
+	NatsSubject      string
+	EventFilePath    string
+
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxBackoff   time.Duration
+	OutboxRetention    time.Duration
+
+	GatewayEnabled         bool
+	GatewayTemplate        string
+	GatewayReloadCommand   string
+	GatewayValidateCommand string
+	// GatewayMaintenanceURL serves maintenance pages for proxies that cannot
+	// answer with a static page themselves, such as Traefik.
+	GatewayMaintenanceURL string
+	// GatewayPollInterval is how often every replica checks for changes to
+	// sync into its proxy.
+	GatewayPollInterval time.Duration
+	// GatewayResyncInterval is how often every replica syncs its proxy even
+	// without a sign of changes, to catch those the poll cannot see.
+	GatewayResyncInterval time.Duration
+
+	HealthCheckEnabled bool
+	HealthCheckWorkers int
+
+	UptimeSampleRetention time.Duration
+	UptimeRollupRetention time.Duration
+
+	NotifyPollInterval time.Duration
+	NotifyMaxAttempts  int
+	NotifyTimeout      time.Duration
+	NotifyRetention    time.Duration
+
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+	TracingServiceName string
+
+	TrashRetention time.Duration
+
+	MaintenancePollInterval time.Duration
 }
 
 var AppConfig Configuration
@@ -71,10 +163,71 @@ func Init() {
 		ImageSaveDir:    getEnvString(imageSaveDir, "images"),
 		Brokers:         kafkaBrokersList,
 		Topic:           getEnvString(kafkaTopic, "automation-events"),
+		CompactedTopic:  getEnvString(compactedTopic, ""),
+
+		KafkaWorkspaceTopics: getEnvBool(workspaceTopics, false),
+
+		SnapshotInterval: getEnvDuration(snapshotInterval, 0),
+
+		EventTransport:   getEnvString(eventTransport, "kafka"),
+		EventSource:      getEnvString(eventSource, "/automation-hub-backend"),
+		EventEncoding:    getEnvString(eventEncoding, "structured"),
+		MemoryBufferSize: getEnvInt(memoryBufferSize, 1024),
+		PgNotifyChannel:  getEnvString(pgNotifyChannel, "automation_events"),
+		NatsURL:          getEnvString(natsURL, "nats://nats:4222"),
+		NatsSubject:      getEnvString(natsSubject, "automation-events"),
+		EventFilePath:    getEnvString(eventFilePath, "events/automation-events.jsonl"),
+
+		OutboxPollInterval: getEnvDuration(outboxPoll, time.Second),
+		OutboxBatchSize:    getEnvInt(outboxBatchSize, 100),
+		OutboxMaxBackoff:   getEnvDuration(outboxMaxBackoff, 5*time.Minute),
+		OutboxRetention:    getEnvDuration(outboxRetention, 7*24*time.Hour),
+
+		GatewayEnabled:         getEnvBool(gatewayEnabled, false),
+		GatewayTemplate:        getEnvString(gatewayTemplate, ""),
+		GatewayReloadCommand:   getEnvString(gatewayReload, ""),
+		GatewayValidateCommand: getEnvString(gatewayValidate, ""),
+		GatewayMaintenanceURL:  getEnvString(maintenanceURL, ""),
+		GatewayPollInterval:    getEnvDuration(gatewayPoll, 2*time.Second),
+		GatewayResyncInterval:  getEnvDuration(gatewayResync, time.Minute),
+
+		HealthCheckEnabled: getEnvBool(healthEnabled, true),
+		HealthCheckWorkers: getEnvInt(healthWorkers, 10),
+
+		UptimeSampleRetention: getEnvDuration(uptimeSamples, 7*24*time.Hour),
+		UptimeRollupRetention: getEnvDuration(uptimeRollups, 400*24*time.Hour),
+
+		NotifyPollInterval: getEnvDuration(notifyPoll, 2*time.Second),
+		NotifyMaxAttempts:  getEnvInt(notifyAttempts, 8),
+		NotifyTimeout:      getEnvDuration(notifyTimeout, 10*time.Second),
+		NotifyRetention:    getEnvDuration(notifyRetention, 30*24*time.Hour),
+
+		TracingExporter:    getEnvString(tracingExporter, "none"),
+		TracingEndpoint:    getEnvString(tracingEndpoint, ""),
+		TracingInsecure:    getEnvBool(tracingInsecure, false),
+		TracingSampleRatio: getEnvFloat(tracingRatio, 1),
+		TracingServiceName: getEnvString(tracingService, "automation-hub-backend"),
+
+		TrashRetention: getEnvDuration(trashRetention, 30*24*time.Hour),
+
+		MaintenancePollInterval: getEnvDuration(maintenancePoll, 30*time.Second),
+	}
+	if err := validateGateway(); err != nil {
+		panic(err)
 	}
 	ensureImageDirExists()
 }
 
+// validateGateway refuses to apply generated configs without validating
+// them first.
+func validateGateway() error {
+	if AppConfig.GatewayEnabled && strings.TrimSpace(AppConfig.GatewayValidateCommand) == "" {
+		return fmt.Errorf("error: %s is required when %s is set, e.g. a script running nginx -t on a config "+
+			"that includes the {staging} directory", gatewayValidate, gatewayEnabled)
+	}
+	return nil
+}
+
 func ensureImageDirExists() {
 	if _, err := os.Stat(AppConfig.ImageSaveDir); os.IsNotExist(err) {
 		err := os.MkdirAll(AppConfig.ImageSaveDir, 0755)
@@ -118,6 +271,39 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		boolVal, err := strconv.ParseBool(value)
+		if err == nil {
+			return boolVal
+		}
+	}
+	log.Printf("Using default value for %s: %v", key, defaultValue)
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return floatVal
+		}
+	}
+	log.Printf("Using default value for %s: %v", key, defaultValue)
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
+			return duration
+		}
+	}
+	log.Printf("Using default value for %s: %v", key, defaultValue)
+	return defaultValue
+}
+
 func getEnvString(key string, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -0,0 +1,187 @@
+package environment
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+// Promotion names the environment a configuration is promoted to.
+type Promotion struct {
+	To string `json:"to"`
+}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// GetAll
+// @Summary Get the environments of an automation
+// @Description Retrieve the environments an automation is deployed in besides its main deployment
+// @Tags Environments
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {array} models.AutomationEnvironment "Successfully retrieved environments"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/environments [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	environments, err := h.service.FindAll(c.Request.Context(), automationID)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, environments)
+}
+
+// Put
+// @Summary Create or replace an environment of an automation
+// @Description Set the host, port and routing options overrides of an environment; unset fields fall back to the automation
+// @Tags Environments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param env path string true "Environment name"
+// @Param environment body models.AutomationEnvironment true "Environment data"
+// @Success 200 {object} models.AutomationEnvironment "Successfully saved environment"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/environments/{env} [put]
+func (h *Handler) Put(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var environment models.AutomationEnvironment
+	if !readBody(c, &environment) {
+		return
+	}
+	environment.Name = c.Param("env")
+
+	saved, err := h.service.Put(c.Request.Context(), automationID, &environment)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// Delete
+// @Summary Remove an environment from an automation
+// @Description Stop deploying an automation in an environment
+// @Tags Environments
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param env path string true "Environment name"
+// @Success 204 "Successfully deleted environment"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/environments/{env} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), automationID, c.Param("env")); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Promote
+// @Summary Promote an environment
+// @Description Copy the host, port and routing options of an environment onto another, creating it if needed
+// @Tags Environments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param env path string true "Environment to promote from"
+// @Param promotion body Promotion true "Environment to promote to"
+// @Success 200 {object} models.AutomationEnvironment "Successfully promoted environment"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/environments/{env}/promote [post]
+func (h *Handler) Promote(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var promotion Promotion
+	if !readBody(c, &promotion) {
+		return
+	}
+
+	promoted, err := h.service.Promote(c.Request.Context(), automationID, c.Param("env"), promotion.To)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, promoted)
+}
+
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for " + param})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readBody(c *gin.Context, value interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound), errors.Is(err, ErrEnvironmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidEnvironment):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNameReserved):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,80 @@
+package environment
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindByAutomation(automationID uuid.UUID) ([]*models.AutomationEnvironment, error)
+	FindByName(automationID uuid.UUID, name string) (*models.AutomationEnvironment, error)
+	Save(environment *models.AutomationEnvironment) (*models.AutomationEnvironment, error)
+	Delete(automationID uuid.UUID, name string) error
+	IsWorkspaceSlug(name string) (bool, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindByAutomation(automationID uuid.UUID) ([]*models.AutomationEnvironment, error) {
+	var environments []*models.AutomationEnvironment
+	err := r.DB.Where("automation_id = ?", automationID).Order("name asc").Find(&environments).Error
+	if err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+func (r *GormRepository) FindByName(automationID uuid.UUID, name string) (*models.AutomationEnvironment, error) {
+	var environment models.AutomationEnvironment
+	err := r.DB.First(&environment, "automation_id = ? AND name = ?", automationID, name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// Save creates the environment when it has no ID yet and replaces it
+// otherwise.
+func (r *GormRepository) Save(environment *models.AutomationEnvironment) (*models.AutomationEnvironment, error) {
+	err := r.DB.Save(environment).Error
+	if err != nil {
+		return nil, err
+	}
+	return environment, nil
+}
+
+// IsWorkspaceSlug reports whether a workspace has name as its slug. Both are
+// the first segment of gateway paths.
+func (r *GormRepository) IsWorkspaceSlug(name string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.Workspace{}).Where("slug = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormRepository) Delete(automationID uuid.UUID, name string) error {
+	return r.DB.Where("automation_id = ? AND name = ?", automationID, name).
+		Delete(&models.AutomationEnvironment{}).Error
+}
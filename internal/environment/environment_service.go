@@ -0,0 +1,201 @@
+package environment
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAutomationNotFound  = errors.New("automation not found")
+	ErrEnvironmentNotFound = errors.New("environment not found")
+	ErrInvalidEnvironment  = errors.New("invalid environment")
+	ErrNameReserved        = errors.New("the name is the slug of a workspace")
+)
+
+type Service interface {
+	FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.AutomationEnvironment, error)
+	Put(ctx context.Context, automationID uuid.UUID, environment *models.AutomationEnvironment) (*models.AutomationEnvironment, error)
+	Delete(ctx context.Context, automationID uuid.UUID, name string) error
+	Promote(ctx context.Context, automationID uuid.UUID, from string, to string) (*models.AutomationEnvironment, error)
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+	outbox         outbox.Repository
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+		outbox:         outboxRepo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.AutomationEnvironment, error) {
+	current, err := findAutomation(s.automationRepo, automationID)
+	if err != nil {
+		return nil, err
+	}
+	return current.Environments, nil
+}
+
+// Put creates the environment or replaces the one with the same name.
+func (s *service) Put(ctx context.Context, automationID uuid.UUID,
+	environment *models.AutomationEnvironment) (*models.AutomationEnvironment, error) {
+	environment.AutomationID = automationID
+	if err := environment.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvironment, err)
+	}
+
+	var saved *models.AutomationEnvironment
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		if _, err := findAutomation(s.automationRepo.WithTx(tx), automationID); err != nil {
+			return err
+		}
+		var err error
+		saved, err = s.save(ctx, tx, environment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *service) Delete(ctx context.Context, automationID uuid.UUID, name string) error {
+	return s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if _, err := findEnvironment(txRepo, automationID, name); err != nil {
+			return err
+		}
+		if err := txRepo.Delete(automationID, name); err != nil {
+			return err
+		}
+		_, err := s.publishChange(ctx, tx, automationID)
+		return err
+	})
+}
+
+// Promote copies the host, port and routing options of one environment onto
+// another, which is created if it does not exist yet. Consumers receive the
+// automation with its environments as they are after the promotion.
+func (s *service) Promote(ctx context.Context, automationID uuid.UUID, from string,
+	to string) (*models.AutomationEnvironment, error) {
+	if from == to {
+		return nil, fmt.Errorf("%w: cannot promote %s onto itself", ErrInvalidEnvironment, from)
+	}
+
+	var promoted *models.AutomationEnvironment
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		source, err := findEnvironment(txRepo, automationID, from)
+		if err != nil {
+			return err
+		}
+
+		target, err := txRepo.FindByName(automationID, to)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			target, err = &models.AutomationEnvironment{AutomationID: automationID, Name: to}, nil
+		}
+		if err != nil {
+			return err
+		}
+		source.Promote(target)
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidEnvironment, err)
+		}
+
+		promoted, err = s.save(ctx, tx, target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// save stores the environment, keeping the ID of an existing one with the
+// same name, and publishes the change.
+func (s *service) save(ctx context.Context, tx *gorm.DB,
+	environment *models.AutomationEnvironment) (*models.AutomationEnvironment, error) {
+	txRepo := s.repo.WithTx(tx)
+	existing, err := txRepo.FindByName(environment.AutomationID, environment.Name)
+	switch {
+	case err == nil:
+		environment.ID = existing.ID
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		environment.ID = uuid.UUID{}
+		// gateway paths start with the workspace slug or the environment name
+		reserved, err := txRepo.IsWorkspaceSlug(environment.Name)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, ErrNameReserved
+		}
+	default:
+		return nil, err
+	}
+
+	if _, err := txRepo.Save(environment); err != nil {
+		return nil, err
+	}
+	current, err := s.publishChange(ctx, tx, environment.AutomationID)
+	if err != nil {
+		return nil, err
+	}
+	return current.Environment(environment.Name), nil
+}
+
+// publishChange validates the automation as deployed in each environment and
+// queues an update event carrying the environments.
+func (s *service) publishChange(ctx context.Context, tx *gorm.DB, automationID uuid.UUID) (*models.Automation, error) {
+	current, err := findAutomation(s.automationRepo.WithTx(tx), automationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, environment := range current.Environments {
+		if err := current.ForEnvironment(environment).Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidEnvironment, environment.Name, err)
+		}
+	}
+	if err := s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, current)); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+func findAutomation(repo automation.Repository, id uuid.UUID) (*models.Automation, error) {
+	current, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+func findEnvironment(repo Repository, automationID uuid.UUID, name string) (*models.AutomationEnvironment, error) {
+	environment, err := repo.FindByName(automationID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+	return environment, nil
+}
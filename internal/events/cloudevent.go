@@ -0,0 +1,120 @@
+package events
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	CloudEventsContentType = "application/cloudevents+json"
+	DataContentType        = "application/json"
+
+	StructuredEncoding = "structured"
+	BinaryEncoding     = "binary"
+
+	cloudEventTypePrefix = "com.automationhub.automation."
+)
+
+// CloudEvent is a CloudEvents 1.0 envelope in structured JSON mode. Actor is
+// an extension attribute naming who triggered the change, Workspace one
+// naming the workspace of the automations and Replay one marking events
+// re-sent by an operator; TraceParent and TraceState are the distributed
+// tracing extension.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	Actor           string          `json:"actor,omitempty"`
+	Workspace       string          `json:"workspace,omitempty"`
+	Replay          bool            `json:"replay,omitempty"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	TraceState      string          `json:"tracestate,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+func CloudEventType(eventType AutomationEventType) string {
+	return cloudEventTypePrefix + string(eventType)
+}
+
+// ToCloudEvent wraps the event payload, in the current schema version, into a
+// CloudEvents envelope.
+func ToCloudEvent(event *AutomationEvent) (*CloudEvent, error) {
+	data, err := json.Marshal(newAutomationDataV1(event))
+	if err != nil {
+		return nil, err
+	}
+
+	ce := &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              event.ID.String(),
+		Source:          config.AppConfig.EventSource,
+		Type:            CloudEventType(event.Type),
+		Time:            event.Time,
+		DataContentType: DataContentType,
+		DataSchema:      SchemaV1,
+		Actor:           event.Actor,
+		Workspace:       event.Workspace,
+		Replay:          event.Replay,
+		TraceParent:     event.TraceParent,
+		TraceState:      event.TraceState,
+		Data:            data,
+	}
+	if event.Automation != nil {
+		ce.Subject = event.Automation.ID.String()
+	}
+	return ce, nil
+}
+
+// DecodeCloudEvent parses a structured CloudEvent, or a legacy pre-CloudEvents
+// message, into an AutomationEvent. Payloads are decoded according to their
+// dataschema so consumers can read every schema version side by side.
+func DecodeCloudEvent(message []byte) (*AutomationEvent, error) {
+	var ce CloudEvent
+	if err := json.Unmarshal(message, &ce); err != nil {
+		return nil, err
+	}
+	if ce.SpecVersion == "" {
+		return decodeLegacy(message)
+	}
+	return ce.AutomationEvent()
+}
+
+// AutomationEvent decodes the event. Events sent without data, such as those
+// of the Postgres publisher, decode to their attributes only.
+func (ce *CloudEvent) AutomationEvent() (*AutomationEvent, error) {
+	switch {
+	case ce.DataSchema == "" && len(ce.Data) == 0:
+		return (&AutomationDataV1{}).toEvent(ce)
+	case ce.DataSchema == SchemaV1:
+		var data AutomationDataV1
+		if err := json.Unmarshal(ce.Data, &data); err != nil {
+			return nil, err
+		}
+		return data.toEvent(ce)
+	default:
+		return nil, fmt.Errorf("unsupported dataschema %q", ce.DataSchema)
+	}
+}
+
+func decodeLegacy(message []byte) (*AutomationEvent, error) {
+	var legacy struct {
+		Type       AutomationEventType `json:"type"`
+		Automation *models.Automation  `json:"automation"`
+	}
+	if err := json.Unmarshal(message, &legacy); err != nil {
+		return nil, err
+	}
+	return &AutomationEvent{
+		Type:       legacy.Type,
+		Automation: legacy.Automation,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"automation-hub-backend/internal/models"
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+)
+
+func replayedEvent(eventType AutomationEventType) *AutomationEvent {
+	event := NewAutomationEvent(context.Background(), eventType, &models.Automation{ID: uuid.New(), Name: "Invoices"})
+	event.Replay = true
+	return event
+}
+
+func TestStructuredCloudEventKeepsReplay(t *testing.T) {
+	for _, replay := range []bool{true, false} {
+		event := replayedEvent(UpdateEvent)
+		event.Replay = replay
+
+		message, err := encode(event)
+		if err != nil {
+			t.Fatalf("encode() error = %v", err)
+		}
+		decoded, err := DecodeCloudEvent(message)
+		if err != nil {
+			t.Fatalf("DecodeCloudEvent(%s) error = %v", message, err)
+		}
+		if decoded.Replay != replay {
+			t.Errorf("decoded Replay = %v, want %v in %s", decoded.Replay, replay, message)
+		}
+	}
+}
+
+func kafkaHeader(msg *sarama.ProducerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestBinaryKafkaMessageMarksReplay(t *testing.T) {
+	publisher := &KafkaPublisher{topic: "automations", encoding: BinaryEncoding}
+	msg, err := publisher.message(replayedEvent(UpdateEvent))
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if replay := kafkaHeader(msg, "ce_replay"); replay != "true" {
+		t.Errorf("ce_replay = %q, want true", replay)
+	}
+
+	msg, err = publisher.message(NewAutomationEvent(context.Background(), UpdateEvent, &models.Automation{}))
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if replay := kafkaHeader(msg, "ce_replay"); replay != "" {
+		t.Errorf("ce_replay = %q for a live event, want none", replay)
+	}
+}
+
+func TestKafkaTombstoneMarksReplay(t *testing.T) {
+	publisher := &KafkaPublisher{topic: "automations", encoding: StructuredEncoding, compacted: true}
+	msg, err := publisher.message(replayedEvent(DeleteEvent))
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if msg.Value != nil {
+		t.Errorf("tombstone value = %v, want nil", msg.Value)
+	}
+	if replay := kafkaHeader(msg, "ce_replay"); replay != "true" {
+		t.Errorf("ce_replay = %q, want true", replay)
+	}
+}
+
+func TestBinaryNatsMessageMarksReplay(t *testing.T) {
+	publisher := &NatsPublisher{subject: "automations", encoding: BinaryEncoding}
+	msg, err := publisher.message(replayedEvent(UpdateEvent))
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if replay := msg.Header.Get("ce-replay"); replay != "true" {
+		t.Errorf("ce-replay = %q, want true", replay)
+	}
+}
@@ -1,16 +1,109 @@
 package events
 
-import "automation-hub-backend/internal/models"
+import (
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/tracing"
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
 
 type AutomationEventType string
 
 const (
-	CreateEvent AutomationEventType = "create"
-	UpdateEvent AutomationEventType = "update"
-	DeleteEvent AutomationEventType = "delete"
+	CreateEvent   AutomationEventType = "create"
+	UpdateEvent   AutomationEventType = "update"
+	DeleteEvent   AutomationEventType = "delete"
+	ReorderEvent  AutomationEventType = "reorder"
+	SnapshotEvent AutomationEventType = "snapshot"
+	// Lifecycle changes have types of their own, so that consumers such as
+	// the gateway can react to them without diffing updates.
+	ActivateEvent    AutomationEventType = "activate"
+	DisableEvent     AutomationEventType = "disable"
+	MaintenanceEvent AutomationEventType = "maintenance"
 )
 
+// StateEventType is the type of the event announcing that an automation
+// entered state.
+func StateEventType(state string) AutomationEventType {
+	switch state {
+	case models.StateDisabled:
+		return DisableEvent
+	case models.StateMaintenance:
+		return MaintenanceEvent
+	default:
+		return ActivateEvent
+	}
+}
+
+// orderKey partitions and orders events that concern the dashboard order as a
+// whole rather than a single automation.
+const orderKey = "automation-order"
+
+// AutomationEvent is the in-process form of an event. The ID and time are
+// fixed when the event is created so that redeliveries carry the same
+// CloudEvents id and consumers can dedupe them.
 type AutomationEvent struct {
-	Type       AutomationEventType `json:"type"`
-	Automation *models.Automation  `json:"automation"`
+	ID          uuid.UUID            `json:"id"`
+	Type        AutomationEventType  `json:"type"`
+	Time        time.Time            `json:"time"`
+	Actor       string               `json:"actor,omitempty"`
+	Workspace   string               `json:"workspace,omitempty"`
+	Automation  *models.Automation   `json:"automation"`
+	Positions   map[uuid.UUID]int    `json:"positions,omitempty"`
+	Automations []*models.Automation `json:"automations,omitempty"`
+	// Replay marks events re-sent by an operator rather than caused by a change.
+	Replay bool `json:"replay,omitempty"`
+	// TraceParent and TraceState hold the W3C trace context of the request
+	// that caused the event, so the trace survives the outbox.
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+}
+
+func NewAutomationEvent(ctx context.Context, eventType AutomationEventType, automation *models.Automation) *AutomationEvent {
+	traceParent, traceState := tracing.TraceParent(ctx)
+	event := &AutomationEvent{
+		ID:          uuid.New(),
+		Type:        eventType,
+		Time:        time.Now().UTC(),
+		Actor:       ActorFromContext(ctx),
+		Automation:  automation,
+		TraceParent: traceParent,
+		TraceState:  traceState,
+	}
+	if automation != nil {
+		event.Workspace = automation.WorkspaceSlug()
+	}
+	return event
+}
+
+// NewReorderEvent carries the complete position of every automation of a
+// workspace after a swap or reorder.
+func NewReorderEvent(ctx context.Context, workspace string, positions map[uuid.UUID]int) *AutomationEvent {
+	event := NewAutomationEvent(ctx, ReorderEvent, nil)
+	event.Workspace = workspace
+	event.Positions = positions
+	return event
+}
+
+// NewSnapshotEvent carries the complete, ordered list of automations of a
+// workspace so that consumers can rebuild their state from scratch.
+func NewSnapshotEvent(ctx context.Context, workspace string, automations []*models.Automation) *AutomationEvent {
+	event := NewAutomationEvent(ctx, SnapshotEvent, nil)
+	event.Workspace = workspace
+	event.Automations = automations
+	return event
+}
+
+// Key is the partition key of the event. Events sharing a key are delivered
+// in order. Each workspace has an order of its own; the default workspace
+// keeps the key it had before workspaces existed.
+func (e *AutomationEvent) Key() string {
+	if e.Automation == nil {
+		if e.Workspace == "" || e.Workspace == models.DefaultWorkspaceSlug {
+			return orderKey
+		}
+		return orderKey + "." + e.Workspace
+	}
+	return e.Automation.ID.String()
 }
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"automation-hub-backend/internal/metrics"
+	"automation-hub-backend/internal/tracing"
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// InstrumentedPublisher records the outcome and latency of every publish
+// under the name of its transport, and traces it as a producer span that
+// continues the trace of the change.
+type InstrumentedPublisher struct {
+	Publisher
+	transport string
+}
+
+func NewInstrumentedPublisher(transport string, publisher Publisher) *InstrumentedPublisher {
+	return &InstrumentedPublisher{
+		Publisher: publisher,
+		transport: transport,
+	}
+}
+
+func (p *InstrumentedPublisher) Publish(event *AutomationEvent) error {
+	ctx := tracing.WithTraceParent(context.Background(), event.TraceParent, event.TraceState)
+	ctx, span := tracing.Start(ctx, "publish "+string(event.Type), trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystem(p.transport),
+			semconv.MessagingOperationPublish,
+			attribute.String("messaging.message.id", event.ID.String()),
+		))
+
+	// the transport propagates the publish span, not the original request
+	traced := *event
+	traced.TraceParent, traced.TraceState = tracing.TraceParent(ctx)
+	if traced.TraceParent == "" {
+		traced.TraceParent, traced.TraceState = event.TraceParent, event.TraceState
+	}
+
+	start := time.Now()
+	err := p.Publisher.Publish(&traced)
+	metrics.ObservePublish(p.transport, time.Since(start), err)
+	tracing.End(span, err)
+	return err
+}
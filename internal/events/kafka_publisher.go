@@ -0,0 +1,180 @@
+package events
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/tracing"
+	"context"
+	"github.com/IBM/sarama"
+	"log"
+	"time"
+)
+
+type KafkaPublisher struct {
+	producer  sarama.SyncProducer
+	topic     string
+	encoding  string
+	compacted bool
+}
+
+func NewKafkaPublisher(brokers []string, topic string, encoding string) (*KafkaPublisher, error) {
+	newConfig := sarama.NewConfig()
+	newConfig.Producer.RequiredAcks = sarama.WaitForAll
+	newConfig.Producer.Retry.Max = 5
+	newConfig.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaPublisher{
+		producer: producer,
+		topic:    topic,
+		encoding: encoding,
+	}, nil
+}
+
+// NewCompactedKafkaPublisher publishes to a log-compacted topic keyed by
+// automation ID. Delete events are sent as tombstones so that compaction
+// eventually drops the automation from the topic.
+func NewCompactedKafkaPublisher(brokers []string, topic string, encoding string) (*KafkaPublisher, error) {
+	publisher, err := NewKafkaPublisher(brokers, topic, encoding)
+	if err != nil {
+		return nil, err
+	}
+	publisher.compacted = true
+	return publisher, nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+func (p *KafkaPublisher) Publish(event *AutomationEvent) error {
+	msg, err := p.message(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.producer.SendMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sent message to Kafka topic %s", msg.Topic)
+	return nil
+}
+
+func (p *KafkaPublisher) message(event *AutomationEvent) (*sarama.ProducerMessage, error) {
+	msg := &sarama.ProducerMessage{
+		Topic: p.topicFor(event),
+		Key:   sarama.StringEncoder(event.Key()),
+	}
+
+	if p.compacted && event.Type == DeleteEvent {
+		// tombstones have no value, so replays are only marked in a header
+		if event.Replay {
+			msg.Headers = append(msg.Headers, header("ce_replay", "true"))
+		}
+		injectTraceContext(msg, event)
+		return msg, nil
+	}
+
+	if p.encoding != BinaryEncoding {
+		message, err := encode(event)
+		if err != nil {
+			return nil, err
+		}
+		msg.Value = sarama.ByteEncoder(message)
+		msg.Headers = []sarama.RecordHeader{header("content-type", CloudEventsContentType)}
+		injectTraceContext(msg, event)
+		return msg, nil
+	}
+
+	// Binary content mode: the attributes travel as ce_ headers and the value
+	// holds only the data.
+	ce, err := ToCloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	msg.Value = sarama.ByteEncoder(ce.Data)
+	msg.Headers = []sarama.RecordHeader{
+		header("ce_specversion", ce.SpecVersion),
+		header("ce_id", ce.ID),
+		header("ce_source", ce.Source),
+		header("ce_type", ce.Type),
+		header("ce_time", ce.Time.Format(time.RFC3339Nano)),
+		header("ce_dataschema", ce.DataSchema),
+		header("content-type", ce.DataContentType),
+	}
+	if ce.Subject != "" {
+		msg.Headers = append(msg.Headers, header("ce_subject", ce.Subject))
+	}
+	if ce.Actor != "" {
+		msg.Headers = append(msg.Headers, header("ce_actor", ce.Actor))
+	}
+	if ce.Workspace != "" {
+		msg.Headers = append(msg.Headers, header("ce_workspace", ce.Workspace))
+	}
+	if ce.Replay {
+		msg.Headers = append(msg.Headers, header("ce_replay", "true"))
+	}
+	injectTraceContext(msg, event)
+	return msg, nil
+}
+
+// topicFor returns the topic of the event. With KAFKA_WORKSPACE_TOPICS set,
+// the events of a workspace go to "<topic>.<workspace>", which must exist or
+// be auto-created by the brokers; the default workspace stays on the topic
+// itself so that existing consumers are unaffected.
+func (p *KafkaPublisher) topicFor(event *AutomationEvent) string {
+	if !config.AppConfig.KafkaWorkspaceTopics || event.Workspace == "" ||
+		event.Workspace == models.DefaultWorkspaceSlug {
+		return p.topic
+	}
+	return p.topic + "." + event.Workspace
+}
+
+// injectTraceContext adds the W3C traceparent and tracestate headers so that
+// consumers continue the trace of the change.
+func injectTraceContext(msg *sarama.ProducerMessage, event *AutomationEvent) {
+	ctx := tracing.WithTraceParent(context.Background(), event.TraceParent, event.TraceState)
+	tracing.Inject(ctx, headerCarrier{msg: msg})
+}
+
+// headerCarrier adapts Kafka record headers to the OpenTelemetry propagators.
+type headerCarrier struct {
+	msg *sarama.ProducerMessage
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key string, value string) {
+	for i, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, header(key, value))
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		keys = append(keys, string(h.Key))
+	}
+	return keys
+}
+
+func header(key string, value string) sarama.RecordHeader {
+	return sarama.RecordHeader{Key: []byte(key), Value: []byte(value)}
+}
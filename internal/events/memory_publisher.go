@@ -0,0 +1,75 @@
+package events
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// ErrPublisherClosed is returned for events published after Close, as can
+// happen while the outbox is still draining at shutdown.
+var ErrPublisherClosed = errors.New("publisher is closed")
+
+// MemoryPublisher hands events to in-process consumers over a buffered
+// channel. It is meant for local development and single-instance deployments
+// that embed the hub and read Events; the hub itself does not read them.
+// The buffer is a ring: when no consumer keeps up, the oldest events are
+// dropped so that publishing, and with it the outbox, never gets stuck.
+type MemoryPublisher struct {
+	mu      sync.Mutex
+	events  chan *AutomationEvent
+	dropped uint64
+	closed  bool
+}
+
+func NewMemoryPublisher(bufferSize int) *MemoryPublisher {
+	return &MemoryPublisher{
+		events: make(chan *AutomationEvent, bufferSize),
+	}
+}
+
+func (p *MemoryPublisher) Events() <-chan *AutomationEvent {
+	return p.events
+}
+
+// Dropped returns how many events were dropped for lack of a consumer.
+func (p *MemoryPublisher) Dropped() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dropped
+}
+
+func (p *MemoryPublisher) Publish(event *AutomationEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	for {
+		select {
+		case p.events <- event:
+			return nil
+		default:
+		}
+
+		select {
+		case <-p.events:
+			if p.dropped == 0 {
+				log.Printf("Memory publisher buffer is full (%d events), dropping the oldest events", cap(p.events))
+			}
+			p.dropped++
+		default:
+		}
+	}
+}
+
+// Close ends Events. It may be called more than once.
+func (p *MemoryPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.events)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryPublisherDropsOldestWhenFull(t *testing.T) {
+	publisher := NewMemoryPublisher(2)
+	published := make([]*AutomationEvent, 3)
+	for i := range published {
+		published[i] = NewAutomationEvent(context.Background(), UpdateEvent, nil)
+		if err := publisher.Publish(published[i]); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	if dropped := publisher.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropped)
+	}
+	for _, want := range published[1:] {
+		if got := <-publisher.Events(); got != want {
+			t.Errorf("event %s, want %s", got.ID, want.ID)
+		}
+	}
+}
+
+func TestMemoryPublisherRejectsEventsAfterClose(t *testing.T) {
+	publisher := NewMemoryPublisher(1)
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	err := publisher.Publish(NewAutomationEvent(context.Background(), UpdateEvent, nil))
+	if !errors.Is(err, ErrPublisherClosed) {
+		t.Errorf("Publish() after Close error = %v, want %v", err, ErrPublisherClosed)
+	}
+	if _, open := <-publisher.Events(); open {
+		t.Errorf("Events() is still open after Close")
+	}
+}
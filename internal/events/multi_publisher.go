@@ -0,0 +1,36 @@
+package events
+
+import "errors"
+
+// MultiPublisher fans every event out to several publishers. An error from
+// any of them fails the publish, so the outbox retries the event and every
+// publisher receives it at least once.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{
+		publishers: publishers,
+	}
+}
+
+func (p *MultiPublisher) Publish(event *AutomationEvent) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
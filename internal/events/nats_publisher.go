@@ -0,0 +1,90 @@
+package events
+
+import (
+	"github.com/nats-io/nats.go"
+	"log"
+	"time"
+)
+
+type NatsPublisher struct {
+	conn     *nats.Conn
+	subject  string
+	encoding string
+}
+
+func NewNatsPublisher(url string, subject string, encoding string) (*NatsPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NatsPublisher{
+		conn:     conn,
+		subject:  subject,
+		encoding: encoding,
+	}, nil
+}
+
+func (p *NatsPublisher) Publish(event *AutomationEvent) error {
+	msg, err := p.message(event)
+	if err != nil {
+		return err
+	}
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	// Flush so that a lost connection surfaces here and the outbox retries.
+	if err := p.conn.Flush(); err != nil {
+		return err
+	}
+
+	log.Printf("Sent message to NATS subject %s", p.subject)
+	return nil
+}
+
+func (p *NatsPublisher) message(event *AutomationEvent) (*nats.Msg, error) {
+	msg := nats.NewMsg(p.subject)
+	msg.Header.Set("Automation-Id", event.Key())
+
+	if p.encoding != BinaryEncoding {
+		message, err := encode(event)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = message
+		msg.Header.Set("Content-Type", CloudEventsContentType)
+		return msg, nil
+	}
+
+	ce, err := ToCloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	msg.Data = ce.Data
+	msg.Header.Set("ce-specversion", ce.SpecVersion)
+	msg.Header.Set("ce-id", ce.ID)
+	msg.Header.Set("ce-source", ce.Source)
+	msg.Header.Set("ce-type", ce.Type)
+	msg.Header.Set("ce-time", ce.Time.Format(time.RFC3339Nano))
+	msg.Header.Set("ce-dataschema", ce.DataSchema)
+	msg.Header.Set("Content-Type", ce.DataContentType)
+	if ce.Subject != "" {
+		msg.Header.Set("ce-subject", ce.Subject)
+	}
+	if ce.Actor != "" {
+		msg.Header.Set("ce-actor", ce.Actor)
+	}
+	if ce.Workspace != "" {
+		msg.Header.Set("ce-workspace", ce.Workspace)
+	}
+	if ce.Replay {
+		msg.Header.Set("ce-replay", "true")
+	}
+	return msg, nil
+}
+
+func (p *NatsPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/infra"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+// PostgresPublisher sends events with NOTIFY so consumers can LISTEN on the
+// channel without any broker besides the database the hub already uses.
+// NOTIFY payloads must stay below 8000 bytes, which large descriptions and
+// snapshots of whole workspaces exceed, so events go out without their data:
+// listeners fetch the automation named by the subject, or the automations of
+// the workspace for events without one, from the API.
+type PostgresPublisher struct {
+	db      *gorm.DB
+	channel string
+}
+
+func NewPostgresPublisher(db *gorm.DB, channel string) *PostgresPublisher {
+	return &PostgresPublisher{
+		db:      db,
+		channel: channel,
+	}
+}
+
+func DefaultPostgresPublisher() (*PostgresPublisher, error) {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return NewPostgresPublisher(db, config.AppConfig.PgNotifyChannel), nil
+}
+
+func (p *PostgresPublisher) Publish(event *AutomationEvent) error {
+	message, err := encodeNotification(event)
+	if err != nil {
+		return err
+	}
+
+	return p.db.Exec("SELECT pg_notify(?, ?)", p.channel, string(message)).Error
+}
+
+func (p *PostgresPublisher) Close() error {
+	return nil
+}
+
+// encodeNotification encodes the CloudEvent of event without its data.
+func encodeNotification(event *AutomationEvent) ([]byte, error) {
+	ce, err := ToCloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	ce.DataContentType, ce.DataSchema, ce.Data = "", "", nil
+	return json.Marshal(ce)
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"automation-hub-backend/internal/models"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEncodeNotificationFitsLargeEvents(t *testing.T) {
+	automations := make([]*models.Automation, 50)
+	for i := range automations {
+		automations[i] = &models.Automation{ID: uuid.New(), Name: "Automation",
+			Metadata: models.Metadata{Description: strings.Repeat("x", 10000)}}
+	}
+	snapshot := NewSnapshotEvent(context.Background(), "staging", automations)
+	update := NewAutomationEvent(context.Background(), UpdateEvent, automations[0])
+
+	for _, event := range []*AutomationEvent{snapshot, update} {
+		message, err := encodeNotification(event)
+		if err != nil {
+			t.Fatalf("encodeNotification(%s) error = %v", event.Type, err)
+		}
+		// the largest payload NOTIFY accepts
+		if len(message) >= 8000 {
+			t.Errorf("%s notification has %d bytes, want less than 8000", event.Type, len(message))
+		}
+
+		decoded, err := DecodeCloudEvent(message)
+		if err != nil {
+			t.Fatalf("DecodeCloudEvent(%s) error = %v", message, err)
+		}
+		if decoded.ID != event.ID || decoded.Type != event.Type || decoded.Workspace != event.Workspace {
+			t.Errorf("decoded %s event = %+v, want the ID, type and workspace of %+v", event.Type, decoded, event)
+		}
+	}
+}
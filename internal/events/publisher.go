@@ -0,0 +1,66 @@
+package events
+
+import (
+	"automation-hub-backend/internal/config"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+const (
+	KafkaTransport    = "kafka"
+	MemoryTransport   = "memory"
+	PostgresTransport = "postgres"
+	NatsTransport     = "nats"
+	FileTransport     = "file"
+)
+
+// Publisher delivers automation events to downstream consumers.
+type Publisher interface {
+	Publish(event *AutomationEvent) error
+	Close() error
+}
+
+func NewPublisher(transport string) (Publisher, error) {
+	var (
+		publisher Publisher
+		err       error
+	)
+	switch transport {
+	case KafkaTransport:
+		publisher, err = NewKafkaPublisher(config.AppConfig.Brokers, config.AppConfig.Topic, config.AppConfig.EventEncoding)
+	case MemoryTransport:
+		// only useful with an in-process consumer reading Events, see
+		// MemoryPublisher
+		publisher = NewMemoryPublisher(config.AppConfig.MemoryBufferSize)
+	case PostgresTransport:
+		publisher, err = DefaultPostgresPublisher()
+	case NatsTransport:
+		publisher, err = NewNatsPublisher(config.AppConfig.NatsURL, config.AppConfig.NatsSubject, config.AppConfig.EventEncoding)
+	case FileTransport:
+		publisher, err = NewFilePublisher(config.AppConfig.EventFilePath)
+	default:
+		return nil, fmt.Errorf("unknown event transport %q", transport)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewInstrumentedPublisher(transport, publisher), nil
+}
+
+func DefaultPublisher() Publisher {
+	publisher, err := NewPublisher(config.AppConfig.EventTransport)
+	if err != nil {
+		log.Fatalf("Failed to create default publisher: %v", err)
+	}
+	return publisher
+}
+
+// encode renders the event as a structured-mode CloudEvent.
+func encode(event *AutomationEvent) ([]byte, error) {
+	ce, err := ToCloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ce)
+}
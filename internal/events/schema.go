@@ -0,0 +1,56 @@
+package events
+
+import (
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// Data schema identifiers carried in the CloudEvents dataschema attribute.
+// A new version gets a new identifier and payload type; older ones stay
+// decodable so consumers can migrate at their own pace.
+const (
+	SchemaV1 = "urn:automation-hub:schema:automation-event:v1"
+)
+
+type AutomationDataV1 struct {
+	Automation  *models.Automation   `json:"automation"`
+	OldURLPath  string               `json:"oldUrlPath,omitempty"`
+	Positions   map[uuid.UUID]int    `json:"positions,omitempty"`
+	Automations []*models.Automation `json:"automations,omitempty"`
+}
+
+func newAutomationDataV1(event *AutomationEvent) *AutomationDataV1 {
+	data := &AutomationDataV1{
+		Automation:  event.Automation,
+		Positions:   event.Positions,
+		Automations: event.Automations,
+	}
+	if event.Automation != nil {
+		data.OldURLPath = event.Automation.OldUrlPath
+	}
+	return data
+}
+
+func (d *AutomationDataV1) toEvent(ce *CloudEvent) (*AutomationEvent, error) {
+	id, err := uuid.Parse(ce.ID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Automation != nil && d.OldURLPath != "" {
+		d.Automation.OldUrlPath = d.OldURLPath
+	}
+	return &AutomationEvent{
+		ID:          id,
+		Type:        AutomationEventType(strings.TrimPrefix(ce.Type, cloudEventTypePrefix)),
+		Time:        ce.Time,
+		Actor:       ce.Actor,
+		Workspace:   ce.Workspace,
+		Automation:  d.Automation,
+		Positions:   d.Positions,
+		Automations: d.Automations,
+		Replay:      ce.Replay,
+		TraceParent: ce.TraceParent,
+		TraceState:  ce.TraceState,
+	}, nil
+}
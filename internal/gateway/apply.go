@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+const stagingDirName = ".staging"
+
+// applier moves a generation into the live config directory in steps: it
+// stages the files, validates them, swaps them in and reloads the proxy. When
+// the reload fails the previous generation, read from the live directory
+// before the swap, is restored.
+type applier struct {
+	live      *ConfigWriter
+	staging   *ConfigWriter
+	validator *CommandValidator
+	reloader  *CommandReloader
+	status    *statusRecorder
+	// dirty is set while the live files may differ from what the proxy runs.
+	dirty bool
+}
+
+func newApplier(dir string, validator *CommandValidator, reloader *CommandReloader) *applier {
+	return &applier{
+		live:      NewConfigWriter(dir),
+		staging:   NewConfigWriter(filepath.Join(dir, stagingDirName)),
+		validator: validator,
+		reloader:  reloader,
+		status:    newStatusRecorder(),
+	}
+}
+
+var errInvalid = errors.New("generated gateway config is invalid")
+
+func (a *applier) apply(files map[string][]byte) error {
+	current, err := a.live.ReadAll()
+	if err != nil {
+		return err
+	}
+	if sameFiles(current, files) && !a.dirty {
+		if a.status.get().State == StatusPending {
+			a.status.applied(len(files))
+		}
+		return nil
+	}
+
+	if _, err := a.staging.Replace(files); err != nil {
+		return err
+	}
+	if err := a.validator.Validate(a.staging.Dir()); err != nil {
+		a.status.failed(StatusInvalid, err)
+		return fmt.Errorf("%w: %v", errInvalid, err)
+	}
+
+	if _, err := a.live.Replace(files); err != nil {
+		a.rollback(current, err)
+		return err
+	}
+	if err := a.reloader.Reload(); err != nil {
+		a.rollback(current, err)
+		return err
+	}
+
+	a.dirty = false
+	a.status.applied(len(files))
+	return nil
+}
+
+func (a *applier) rollback(previous map[string][]byte, cause error) {
+	a.status.failed(StatusRolledBack, cause)
+	if _, err := a.live.Replace(previous); err != nil {
+		log.Printf("Failed to restore previous gateway config: %v", err)
+		a.dirty = true
+		return
+	}
+	if err := a.reloader.Reload(); err != nil {
+		log.Printf("Failed to reload previous gateway config: %v", err)
+		a.dirty = true
+		return
+	}
+	a.dirty = false
+}
+
+func sameFiles(a map[string][]byte, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, content := range a {
+		other, ok := b[name]
+		if !ok || !bytes.Equal(content, other) {
+			return false
+		}
+	}
+	return true
+}
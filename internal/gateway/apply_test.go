@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stubScript writes an executable shell script standing in for nginx.
+func stubScript(t *testing.T, dir string, name string, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newStubApplier returns an applier for a fresh config directory whose
+// validation rejects generations containing "invalid" and whose reload fails
+// while the live config contains "broken", like nginx -t and nginx -s reload
+// would.
+func newStubApplier(t *testing.T) (*applier, string) {
+	t.Helper()
+	root := t.TempDir()
+	live := filepath.Join(root, "conf")
+	validate := stubScript(t, root, "validate.sh",
+		`! grep -rq invalid "$GATEWAY_STAGING_DIR"`)
+	reload := stubScript(t, root, "reload.sh",
+		`! grep -q broken "`+live+`"/automation-*`)
+	return newApplier(live, NewCommandValidator(validate+" {staging}"), NewCommandReloader(reload)), live
+}
+
+func readLive(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	files, err := NewConfigWriter(dir).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestApplyValidGeneration(t *testing.T) {
+	a, live := newStubApplier(t)
+	files := map[string][]byte{FileName("demo"): []byte("location /demo/ {}\n")}
+
+	if err := a.apply(files); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if got := readLive(t, live); !sameFiles(got, files) {
+		t.Errorf("live config = %q, want %q", got, files)
+	}
+	status := a.status.get()
+	if status.State != StatusApplied || status.Generation != 1 || status.Files != 1 {
+		t.Errorf("status = %+v, want applied generation 1 with 1 file", status)
+	}
+}
+
+func TestApplyRejectsInvalidGeneration(t *testing.T) {
+	a, live := newStubApplier(t)
+	good := map[string][]byte{FileName("demo"): []byte("location /demo/ {}\n")}
+	if err := a.apply(good); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string][]byte{FileName("demo"): []byte("location /demo/ { invalid }\n")}
+	err := a.apply(invalid)
+	if !errors.Is(err, errInvalid) {
+		t.Fatalf("apply() error = %v, want %v", err, errInvalid)
+	}
+	if got := readLive(t, live); !sameFiles(got, good) {
+		t.Errorf("live config = %q, want the previous generation %q", got, good)
+	}
+	status := a.status.get()
+	if status.State != StatusInvalid || status.Generation != 1 || status.Error == "" {
+		t.Errorf("status = %+v, want invalid with generation 1 and an error", status)
+	}
+}
+
+func TestApplyRollsBackFailedReload(t *testing.T) {
+	a, live := newStubApplier(t)
+	good := map[string][]byte{FileName("demo"): []byte("location /demo/ {}\n")}
+	if err := a.apply(good); err != nil {
+		t.Fatal(err)
+	}
+
+	broken := map[string][]byte{
+		FileName("demo"):  []byte("location /demo/ {}\n"),
+		FileName("other"): []byte("location /other/ { broken }\n"),
+	}
+	if err := a.apply(broken); err == nil {
+		t.Fatal("apply() error = nil, want the reload failure")
+	}
+	if got := readLive(t, live); !sameFiles(got, good) {
+		t.Errorf("live config = %q, want the previous generation %q", got, good)
+	}
+	status := a.status.get()
+	if status.State != StatusRolledBack || status.Generation != 1 || status.Error == "" {
+		t.Errorf("status = %+v, want rolled back with generation 1 and an error", status)
+	}
+	if a.dirty {
+		t.Error("applier is dirty after the previous generation was reloaded")
+	}
+}
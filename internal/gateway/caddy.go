@@ -0,0 +1,309 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// caddyServerName names the HTTP server in the generated Caddy JSON config.
+	caddyServerName = "automation-hub"
+	// caddyFailDuration is how long Caddy avoids an upstream after a failed
+	// request, which is what lets it fail over between upstreams.
+	caddyFailDuration = "30s"
+)
+
+// CaddyfileExporter renders one block per route, meant to be imported into a
+// site block of a Caddyfile. Websockets need no configuration in Caddy.
+type CaddyfileExporter struct{}
+
+func (e *CaddyfileExporter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (e *CaddyfileExporter) Export(routes []*Route) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# Managed by automation-hub-backend. Do not edit by hand.\n")
+	for _, route := range routes {
+		directive := "handle_path"
+		if route.PreservePath {
+			directive = "handle"
+		}
+		if route.Redirect != "" {
+			fmt.Fprintf(&buf, "\n# %s (%s)\nhandle_path %s/* {\n\tredir %s{uri} 308\n}\n", route.Key(), route.ID,
+				route.Prefix(), route.Redirect)
+			continue
+		}
+		if route.Maintenance {
+			fmt.Fprintf(&buf, "\n# %s (%s)\nhandle %s/* {\n", route.Key(), route.ID, route.Prefix())
+			buf.WriteString("\theader Content-Type \"text/html; charset=utf-8\"\n")
+			if route.RetryAfter != "" {
+				fmt.Fprintf(&buf, "\theader Retry-After \"%s\"\n", route.RetryAfter)
+			}
+			fmt.Fprintf(&buf, "\trespond \"%s\" 503\n}\n", route.MaintenancePage)
+			continue
+		}
+		fmt.Fprintf(&buf, "\n# %s (%s)\n%s %s/* {\n", route.Key(), route.ID, directive, route.Prefix())
+		if route.MaxBodySize > 0 {
+			fmt.Fprintf(&buf, "\trequest_body {\n\t\tmax_size %dMB\n\t}\n", route.MaxBodySize)
+		}
+
+		buf.WriteString("\treverse_proxy")
+		for _, upstream := range caddyUpstreamOrder(route) {
+			buf.WriteString(" " + upstream.Address())
+		}
+		buf.WriteString(" {\n")
+		if route.Balanced() {
+			policy, weights := caddyPolicy(route)
+			buf.WriteString("\t\tlb_policy " + policy)
+			for _, weight := range weights {
+				fmt.Fprintf(&buf, " %d", weight)
+			}
+			buf.WriteString("\n\t\tfail_duration " + caddyFailDuration + "\n")
+		}
+		for _, header := range route.RequestHeaders {
+			fmt.Fprintf(&buf, "\t\theader_up %s \"%s\"\n", header.Name, header.Value)
+		}
+		for _, header := range route.ResponseHeaders {
+			fmt.Fprintf(&buf, "\t\theader_down %s \"%s\"\n", header.Name, header.Value)
+		}
+		if route.ConnectTimeout > 0 || route.ReadTimeout > 0 {
+			buf.WriteString("\t\ttransport http {\n")
+			if route.ConnectTimeout > 0 {
+				fmt.Fprintf(&buf, "\t\t\tdial_timeout %s\n", seconds(route.ConnectTimeout))
+			}
+			if route.ReadTimeout > 0 {
+				fmt.Fprintf(&buf, "\t\t\tread_timeout %s\n", seconds(route.ReadTimeout))
+			}
+			buf.WriteString("\t\t}\n")
+		}
+		buf.WriteString("\t}\n}\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// CaddyJSONExporter renders a complete Caddy JSON config that can be loaded
+// through the Caddy admin API.
+type CaddyJSONExporter struct{}
+
+type caddyConfig struct {
+	Apps caddyApps `json:"apps"`
+}
+
+type caddyApps struct {
+	HTTP caddyHTTP `json:"http"`
+}
+
+type caddyHTTP struct {
+	Servers map[string]caddyServer `json:"servers"`
+}
+
+type caddyServer struct {
+	Listen []string     `json:"listen"`
+	Routes []caddyRoute `json:"routes"`
+}
+
+type caddyRoute struct {
+	Match  []caddyMatch   `json:"match,omitempty"`
+	Handle []caddyHandler `json:"handle"`
+}
+
+type caddyMatch struct {
+	Path []string `json:"path"`
+}
+
+type caddyHandler struct {
+	Handler         string              `json:"handler"`
+	Routes          []caddyRoute        `json:"routes,omitempty"`
+	StripPathPrefix string              `json:"strip_path_prefix,omitempty"`
+	MaxSize         int64               `json:"max_size,omitempty"`
+	Upstreams       []caddyUpstream     `json:"upstreams,omitempty"`
+	LoadBalancing   *caddyLoadBalancing `json:"load_balancing,omitempty"`
+	HealthChecks    *caddyHealthChecks  `json:"health_checks,omitempty"`
+	Headers         *caddyHeaders       `json:"headers,omitempty"`
+	Transport       *caddyTransport     `json:"transport,omitempty"`
+	// Response is used by the headers handler, StatusCode and Body by the
+	// static_response handler.
+	Response   *caddyHeaderOps `json:"response,omitempty"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Body       string          `json:"body,omitempty"`
+}
+
+type caddyUpstream struct {
+	Dial string `json:"dial"`
+}
+
+type caddyLoadBalancing struct {
+	SelectionPolicy caddySelectionPolicy `json:"selection_policy"`
+}
+
+type caddySelectionPolicy struct {
+	Policy  string `json:"policy"`
+	Weights []int  `json:"weights,omitempty"`
+}
+
+type caddyHealthChecks struct {
+	Passive caddyPassiveHealthCheck `json:"passive"`
+}
+
+type caddyPassiveHealthCheck struct {
+	FailDuration string `json:"fail_duration"`
+}
+
+type caddyHeaders struct {
+	Request  *caddyHeaderOps `json:"request,omitempty"`
+	Response *caddyHeaderOps `json:"response,omitempty"`
+}
+
+type caddyHeaderOps struct {
+	Set map[string][]string `json:"set"`
+}
+
+type caddyTransport struct {
+	Protocol    string `json:"protocol"`
+	DialTimeout string `json:"dial_timeout,omitempty"`
+	ReadTimeout string `json:"read_timeout,omitempty"`
+}
+
+func (e *CaddyJSONExporter) ContentType() string {
+	return "application/json"
+}
+
+func (e *CaddyJSONExporter) Export(routes []*Route) ([]byte, error) {
+	caddyRoutes := make([]caddyRoute, 0, len(routes))
+	for _, route := range routes {
+		if route.Redirect != "" {
+			caddyRoutes = append(caddyRoutes, caddyRoute{
+				Match:  []caddyMatch{{Path: []string{route.Prefix() + "/*"}}},
+				Handle: caddyRedirect(route),
+			})
+			continue
+		}
+		if route.Maintenance {
+			caddyRoutes = append(caddyRoutes, caddyRoute{
+				Match:  []caddyMatch{{Path: []string{route.Prefix() + "/*"}}},
+				Handle: caddyMaintenance(route),
+			})
+			continue
+		}
+
+		var handlers []caddyHandler
+		if route.MaxBodySize > 0 {
+			handlers = append(handlers, caddyHandler{Handler: "request_body", MaxSize: int64(route.MaxBodySize) * 1024 * 1024})
+		}
+		if !route.PreservePath {
+			handlers = append(handlers, caddyHandler{Handler: "rewrite", StripPathPrefix: route.Prefix()})
+		}
+		handlers = append(handlers, caddyReverseProxy(route))
+
+		caddyRoutes = append(caddyRoutes, caddyRoute{
+			Match: []caddyMatch{{Path: []string{route.Prefix() + "/*"}}},
+			Handle: []caddyHandler{{
+				Handler: "subroute",
+				Routes:  []caddyRoute{{Handle: handlers}},
+			}},
+		})
+	}
+
+	cfg := caddyConfig{Apps: caddyApps{HTTP: caddyHTTP{Servers: map[string]caddyServer{
+		caddyServerName: {Listen: []string{":80"}, Routes: caddyRoutes},
+	}}}}
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+func caddyReverseProxy(route *Route) caddyHandler {
+	proxy := caddyHandler{Handler: "reverse_proxy"}
+	for _, upstream := range caddyUpstreamOrder(route) {
+		proxy.Upstreams = append(proxy.Upstreams, caddyUpstream{Dial: upstream.Address()})
+	}
+	if route.Balanced() {
+		policy, weights := caddyPolicy(route)
+		proxy.LoadBalancing = &caddyLoadBalancing{SelectionPolicy: caddySelectionPolicy{Policy: policy, Weights: weights}}
+		proxy.HealthChecks = &caddyHealthChecks{Passive: caddyPassiveHealthCheck{FailDuration: caddyFailDuration}}
+	}
+	if len(route.RequestHeaders) > 0 || len(route.ResponseHeaders) > 0 {
+		proxy.Headers = &caddyHeaders{
+			Request:  caddyHeaderSet(route.RequestHeaders),
+			Response: caddyHeaderSet(route.ResponseHeaders),
+		}
+	}
+	if route.ConnectTimeout > 0 || route.ReadTimeout > 0 {
+		proxy.Transport = &caddyTransport{
+			Protocol:    "http",
+			DialTimeout: seconds(route.ConnectTimeout),
+			ReadTimeout: seconds(route.ReadTimeout),
+		}
+	}
+	return proxy
+}
+
+// caddyMaintenance answers with the maintenance page of the route. The
+// headers of static_response share their key with those of reverse_proxy in
+// caddyHandler, so a headers handler sets them instead.
+func caddyMaintenance(route *Route) []caddyHandler {
+	headers := []Header{{Name: "Content-Type", Value: "text/html; charset=utf-8"}}
+	if route.RetryAfter != "" {
+		headers = append(headers, Header{Name: "Retry-After", Value: route.RetryAfter})
+	}
+	return []caddyHandler{
+		{Handler: "headers", Response: caddyHeaderSet(headers)},
+		{Handler: "static_response", StatusCode: http.StatusServiceUnavailable, Body: route.MaintenancePage},
+	}
+}
+
+// caddyRedirect redirects to the same request below the redirect target of
+// the route. The Location header is set like the maintenance headers.
+func caddyRedirect(route *Route) []caddyHandler {
+	location := []Header{{Name: "Location", Value: route.Redirect + "{http.request.uri}"}}
+	return []caddyHandler{
+		{Handler: "rewrite", StripPathPrefix: route.Prefix()},
+		{Handler: "headers", Response: caddyHeaderSet(location)},
+		{Handler: "static_response", StatusCode: http.StatusPermanentRedirect},
+	}
+}
+
+// caddyUpstreamOrder lists the primaries before the backups, which is the
+// order the "first" policy tries them in.
+func caddyUpstreamOrder(route *Route) []Upstream {
+	return append(route.Primaries(), route.Backups()...)
+}
+
+// caddyPolicy maps the route policy to a Caddy selection policy. Caddy has no
+// backup upstreams, so a route with backups always uses the first available
+// upstream, trying primaries first.
+func caddyPolicy(route *Route) (string, []int) {
+	if len(route.Backups()) > 0 {
+		return "first", nil
+	}
+	switch route.Policy {
+	case models.LeastConnPolicy:
+		return "least_conn", nil
+	case models.IPHashPolicy:
+		return "ip_hash", nil
+	}
+
+	weighted := false
+	weights := make([]int, 0, len(route.Upstreams))
+	for _, upstream := range route.Upstreams {
+		weights = append(weights, upstream.Weight)
+		weighted = weighted || upstream.Weight > 1
+	}
+	if weighted {
+		return "weighted_round_robin", weights
+	}
+	return "round_robin", nil
+}
+
+func caddyHeaderSet(headers []Header) *caddyHeaderOps {
+	if len(headers) == 0 {
+		return nil
+	}
+	set := make(map[string][]string, len(headers))
+	for _, header := range headers {
+		set[header.Name] = []string{header.Value}
+	}
+	return &caddyHeaderOps{Set: set}
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	commandTimeout = 30 * time.Second
+
+	// stagingPlaceholder is replaced by the staging directory in the
+	// validation command, which also receives it as GATEWAY_STAGING_DIR.
+	stagingPlaceholder = "{staging}"
+)
+
+// CommandValidator checks a staged generation with a command such as
+// `nginx -t`. An empty command accepts every generation.
+type CommandValidator struct {
+	command []string
+}
+
+func NewCommandValidator(command string) *CommandValidator {
+	return &CommandValidator{command: strings.Fields(command)}
+}
+
+func (v *CommandValidator) Validate(stagingDir string) error {
+	command := make([]string, len(v.command))
+	for i, arg := range v.command {
+		command[i] = strings.ReplaceAll(arg, stagingPlaceholder, stagingDir)
+	}
+	return run(command, "GATEWAY_STAGING_DIR="+stagingDir)
+}
+
+// CommandReloader reloads the proxy by running a command, by default
+// `docker exec <NginxContainer> nginx -s reload`.
+type CommandReloader struct {
+	command []string
+}
+
+func NewCommandReloader(command string) *CommandReloader {
+	return &CommandReloader{command: strings.Fields(command)}
+}
+
+func (r *CommandReloader) Reload() error {
+	return run(r.command)
+}
+
+func run(command []string, env ...string) error {
+	if len(command) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", strings.Join(command, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	filePrefix     = "automation-"
+	fileSuffix     = ".conf"
+	upstreamSuffix = ".upstream"
+)
+
+// ConfigWriter owns the automation-*.conf and automation-*.upstream files in
+// a directory. Other files in the directory are never touched.
+type ConfigWriter struct {
+	dir string
+}
+
+func NewConfigWriter(dir string) *ConfigWriter {
+	return &ConfigWriter{dir: dir}
+}
+
+func FileName(urlPath string) string {
+	return filePrefix + filepath.Base(urlPath) + fileSuffix
+}
+
+// UpstreamFileName is the file holding the upstream block of an automation.
+// nginx must include these files in its http block.
+func UpstreamFileName(urlPath string) string {
+	return filePrefix + filepath.Base(urlPath) + upstreamSuffix
+}
+
+func (w *ConfigWriter) Dir() string {
+	return w.dir
+}
+
+// Write atomically replaces the file by writing a temporary file in the same
+// directory and renaming it. It reports whether the content changed.
+func (w *ConfigWriter) Write(name string, content []byte) (bool, error) {
+	path := filepath.Join(w.dir, name)
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(w.dir, "."+name+".tmp-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove deletes the file and reports whether it existed.
+func (w *ConfigWriter) Remove(name string) (bool, error) {
+	err := os.Remove(filepath.Join(w.dir, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns the names of the files managed by the hub.
+func (w *ConfigWriter) List() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, filePrefix) &&
+			(strings.HasSuffix(name, fileSuffix) || strings.HasSuffix(name, upstreamSuffix)) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ReadAll returns the content of every managed file.
+func (w *ConfigWriter) ReadAll() (map[string][]byte, error) {
+	names, err := w.List()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(names))
+	for _, name := range names {
+		content, errRead := os.ReadFile(filepath.Join(w.dir, name))
+		if errRead != nil {
+			return nil, errRead
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+// Replace makes the managed files of the directory exactly files. It reports
+// whether anything changed.
+func (w *ConfigWriter) Replace(files map[string][]byte) (bool, error) {
+	changed := false
+	for name, content := range files {
+		written, err := w.Write(name, content)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || written
+	}
+
+	existing, err := w.List()
+	if err != nil {
+		return changed, err
+	}
+	for _, name := range existing {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		removed, errRemove := w.Remove(name)
+		if errRemove != nil {
+			return changed, errRemove
+		}
+		changed = changed || removed
+	}
+	return changed, nil
+}
@@ -0,0 +1,304 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	envoyRouteConfigType = "type.googleapis.com/envoy.config.route.v3.RouteConfiguration"
+	envoyClusterType     = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+	envoyBufferPerRoute  = "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.BufferPerRoute"
+	envoyBufferFilter    = "envoy.filters.http.buffer"
+	envoyRouteConfigName = "automation-hub"
+	envoyConnectTimeout  = "5s"
+)
+
+// EnvoyRoutesExporter renders the routes as an RDS resource file. The
+// clusters it references come from EnvoyClustersExporter.
+type EnvoyRoutesExporter struct{}
+
+// EnvoyClustersExporter renders one CDS cluster per route.
+type EnvoyClustersExporter struct{}
+
+type envoyResources struct {
+	Resources []interface{} `json:"resources"`
+}
+
+type envoyRouteConfiguration struct {
+	Type         string             `json:"@type"`
+	Name         string             `json:"name"`
+	VirtualHosts []envoyVirtualHost `json:"virtual_hosts"`
+}
+
+type envoyVirtualHost struct {
+	Name    string       `json:"name"`
+	Domains []string     `json:"domains"`
+	Routes  []envoyRoute `json:"routes"`
+}
+
+type envoyRoute struct {
+	Name                 string                 `json:"name"`
+	Match                envoyRouteMatch        `json:"match"`
+	Route                *envoyRouteTo          `json:"route,omitempty"`
+	DirectResponse       *envoyDirectResponse   `json:"direct_response,omitempty"`
+	Redirect             *envoyRedirect         `json:"redirect,omitempty"`
+	RequestHeadersToAdd  []envoyHeaderOption    `json:"request_headers_to_add,omitempty"`
+	ResponseHeadersToAdd []envoyHeaderOption    `json:"response_headers_to_add,omitempty"`
+	TypedPerFilterConfig map[string]interface{} `json:"typed_per_filter_config,omitempty"`
+}
+
+type envoyHeaderOption struct {
+	Header       envoyHeaderValue `json:"header"`
+	AppendAction string           `json:"append_action"`
+}
+
+type envoyHeaderValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type envoyBufferConfig struct {
+	Type   string      `json:"@type"`
+	Buffer envoyBuffer `json:"buffer"`
+}
+
+type envoyBuffer struct {
+	MaxRequestBytes int64 `json:"max_request_bytes"`
+}
+
+type envoyRouteMatch struct {
+	Prefix string `json:"prefix"`
+}
+
+type envoyRouteTo struct {
+	Cluster        string               `json:"cluster"`
+	PrefixRewrite  string               `json:"prefix_rewrite,omitempty"`
+	Timeout        string               `json:"timeout,omitempty"`
+	UpgradeConfigs []envoyUpgradeConfig `json:"upgrade_configs,omitempty"`
+	HashPolicy     []envoyHashPolicy    `json:"hash_policy,omitempty"`
+}
+
+type envoyDirectResponse struct {
+	Status int             `json:"status"`
+	Body   envoyDataSource `json:"body"`
+}
+
+type envoyRedirect struct {
+	PrefixRewrite string `json:"prefix_rewrite"`
+	ResponseCode  string `json:"response_code"`
+}
+
+type envoyDataSource struct {
+	InlineString string `json:"inline_string"`
+}
+
+type envoyHashPolicy struct {
+	ConnectionProperties envoyConnectionProperties `json:"connection_properties"`
+}
+
+type envoyConnectionProperties struct {
+	SourceIP bool `json:"source_ip"`
+}
+
+type envoyUpgradeConfig struct {
+	UpgradeType string `json:"upgrade_type"`
+}
+
+type envoyCluster struct {
+	Type           string              `json:"@type"`
+	Name           string              `json:"name"`
+	ClusterType    string              `json:"type"`
+	ConnectTimeout string              `json:"connect_timeout"`
+	LbPolicy       string              `json:"lb_policy,omitempty"`
+	LoadAssignment envoyLoadAssignment `json:"load_assignment"`
+}
+
+type envoyLoadAssignment struct {
+	ClusterName string                     `json:"cluster_name"`
+	Endpoints   []envoyLocalityLbEndpoints `json:"endpoints"`
+}
+
+type envoyLocalityLbEndpoints struct {
+	LbEndpoints []envoyLbEndpoint `json:"lb_endpoints"`
+	Priority    int               `json:"priority,omitempty"`
+}
+
+type envoyLbEndpoint struct {
+	Endpoint            envoyEndpoint `json:"endpoint"`
+	LoadBalancingWeight int           `json:"load_balancing_weight,omitempty"`
+}
+
+type envoyEndpoint struct {
+	Address envoyAddress `json:"address"`
+}
+
+type envoyAddress struct {
+	SocketAddress envoySocketAddress `json:"socket_address"`
+}
+
+type envoySocketAddress struct {
+	Address   string `json:"address"`
+	PortValue int    `json:"port_value"`
+}
+
+func (e *EnvoyRoutesExporter) ContentType() string {
+	return "application/json"
+}
+
+func (e *EnvoyRoutesExporter) Export(routes []*Route) ([]byte, error) {
+	envoyRoutes := make([]envoyRoute, 0, len(routes))
+	for _, route := range routes {
+		envoyRoutes = append(envoyRoutes, newEnvoyRoute(route))
+	}
+
+	routeConfig := envoyRouteConfiguration{
+		Type: envoyRouteConfigType,
+		Name: envoyRouteConfigName,
+		VirtualHosts: []envoyVirtualHost{{
+			Name:    envoyRouteConfigName,
+			Domains: []string{"*"},
+			Routes:  envoyRoutes,
+		}},
+	}
+	return json.MarshalIndent(envoyResources{Resources: []interface{}{routeConfig}}, "", "  ")
+}
+
+func newEnvoyRoute(route *Route) envoyRoute {
+	if route.Redirect != "" {
+		return envoyRoute{
+			Name:     route.ServiceName(),
+			Match:    envoyRouteMatch{Prefix: route.Prefix() + "/"},
+			Redirect: &envoyRedirect{PrefixRewrite: route.Redirect + "/", ResponseCode: "PERMANENT_REDIRECT"},
+		}
+	}
+	if route.Maintenance {
+		return newEnvoyMaintenanceRoute(route)
+	}
+	r := envoyRoute{
+		Name:                 route.ServiceName(),
+		Match:                envoyRouteMatch{Prefix: route.Prefix() + "/"},
+		Route:                &envoyRouteTo{Cluster: route.ServiceName(), Timeout: seconds(route.ReadTimeout)},
+		RequestHeadersToAdd:  envoyHeaders(route.RequestHeaders),
+		ResponseHeadersToAdd: envoyHeaders(route.ResponseHeaders),
+	}
+	if !route.PreservePath {
+		r.Route.PrefixRewrite = "/"
+	}
+	if route.Policy == models.IPHashPolicy && route.Balanced() {
+		r.Route.HashPolicy = []envoyHashPolicy{{ConnectionProperties: envoyConnectionProperties{SourceIP: true}}}
+	}
+	if route.Websocket {
+		r.Route.UpgradeConfigs = []envoyUpgradeConfig{{UpgradeType: "websocket"}}
+	}
+	if route.MaxBodySize > 0 {
+		r.TypedPerFilterConfig = map[string]interface{}{
+			envoyBufferFilter: envoyBufferConfig{
+				Type:   envoyBufferPerRoute,
+				Buffer: envoyBuffer{MaxRequestBytes: int64(route.MaxBodySize) * 1024 * 1024},
+			},
+		}
+	}
+	return r
+}
+
+// newEnvoyMaintenanceRoute answers with the maintenance page of the route
+// instead of routing to its cluster.
+func newEnvoyMaintenanceRoute(route *Route) envoyRoute {
+	headers := []Header{{Name: "Content-Type", Value: "text/html; charset=utf-8"}}
+	if route.RetryAfter != "" {
+		headers = append(headers, Header{Name: "Retry-After", Value: route.RetryAfter})
+	}
+	return envoyRoute{
+		Name:  route.ServiceName(),
+		Match: envoyRouteMatch{Prefix: route.Prefix() + "/"},
+		DirectResponse: &envoyDirectResponse{
+			Status: http.StatusServiceUnavailable,
+			Body:   envoyDataSource{InlineString: route.MaintenancePage},
+		},
+		ResponseHeadersToAdd: envoyHeaders(headers),
+	}
+}
+
+func envoyHeaders(headers []Header) []envoyHeaderOption {
+	var options []envoyHeaderOption
+	for _, header := range headers {
+		options = append(options, envoyHeaderOption{
+			Header:       envoyHeaderValue{Key: header.Name, Value: header.Value},
+			AppendAction: "OVERWRITE_IF_EXISTS_OR_ADD",
+		})
+	}
+	return options
+}
+
+func (e *EnvoyClustersExporter) ContentType() string {
+	return "application/json"
+}
+
+func (e *EnvoyClustersExporter) Export(routes []*Route) ([]byte, error) {
+	clusters := make([]interface{}, 0, len(routes))
+	for _, route := range routes {
+		// redirects and routes in maintenance do not reach their upstreams
+		if route.Maintenance || route.Redirect != "" {
+			continue
+		}
+		// Backups form a lower priority that Envoy only uses once the
+		// primaries are unhealthy.
+		endpoints := []envoyLocalityLbEndpoints{{LbEndpoints: envoyEndpoints(route, route.Primaries())}}
+		if backups := route.Backups(); len(backups) > 0 {
+			endpoints = append(endpoints, envoyLocalityLbEndpoints{
+				LbEndpoints: envoyEndpoints(route, backups),
+				Priority:    1,
+			})
+		}
+
+		connectTimeout := envoyConnectTimeout
+		if route.ConnectTimeout > 0 {
+			connectTimeout = seconds(route.ConnectTimeout)
+		}
+
+		clusters = append(clusters, envoyCluster{
+			Type:           envoyClusterType,
+			Name:           route.ServiceName(),
+			ClusterType:    "STRICT_DNS",
+			ConnectTimeout: connectTimeout,
+			LbPolicy:       envoyLbPolicy(route),
+			LoadAssignment: envoyLoadAssignment{
+				ClusterName: route.ServiceName(),
+				Endpoints:   endpoints,
+			},
+		})
+	}
+	return json.MarshalIndent(envoyResources{Resources: clusters}, "", "  ")
+}
+
+func envoyEndpoints(route *Route, upstreams []Upstream) []envoyLbEndpoint {
+	var endpoints []envoyLbEndpoint
+	for _, upstream := range upstreams {
+		endpoint := envoyLbEndpoint{Endpoint: envoyEndpoint{Address: envoyAddress{
+			SocketAddress: envoySocketAddress{Address: upstream.Host, PortValue: upstream.Port},
+		}}}
+		if route.Balanced() && upstream.Weight > 1 {
+			endpoint.LoadBalancingWeight = upstream.Weight
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// envoyLbPolicy maps the route policy to a cluster lb_policy. ip_hash uses a
+// ring hash over the source address set in the route's hash_policy.
+func envoyLbPolicy(route *Route) string {
+	if !route.Balanced() {
+		return ""
+	}
+	switch route.Policy {
+	case models.LeastConnPolicy:
+		return "LEAST_REQUEST"
+	case models.IPHashPolicy:
+		return "RING_HASH"
+	default:
+		return "ROUND_ROBIN"
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/config"
+	"fmt"
+	"sort"
+)
+
+const (
+	NginxFormat         = "nginx"
+	TraefikFormat       = "traefik"
+	TraefikTomlFormat   = "traefik-toml"
+	CaddyfileFormat     = "caddyfile"
+	CaddyJSONFormat     = "caddy-json"
+	EnvoyFormat         = "envoy"
+	EnvoyClustersFormat = "envoy-clusters"
+)
+
+// Exporter renders the routes in the configuration format of a proxy.
+type Exporter interface {
+	ContentType() string
+	Export(routes []*Route) ([]byte, error)
+}
+
+func NewExporter(format string, nginx *NginxRenderer) (Exporter, error) {
+	switch format {
+	case NginxFormat:
+		return nginx, nil
+	case TraefikFormat:
+		return &TraefikExporter{toml: false, maintenanceURL: config.AppConfig.GatewayMaintenanceURL}, nil
+	case TraefikTomlFormat:
+		return &TraefikExporter{toml: true, maintenanceURL: config.AppConfig.GatewayMaintenanceURL}, nil
+	case CaddyfileFormat:
+		return &CaddyfileExporter{}, nil
+	case CaddyJSONFormat:
+		return &CaddyJSONExporter{}, nil
+	case EnvoyFormat:
+		return &EnvoyRoutesExporter{}, nil
+	case EnvoyClustersFormat:
+		return &EnvoyClustersExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown gateway config format %q. Supported formats are: %v", format, Formats())
+	}
+}
+
+func Formats() []string {
+	formats := []string{NginxFormat, TraefikFormat, TraefikTomlFormat, CaddyfileFormat, CaddyJSONFormat,
+		EnvoyFormat, EnvoyClustersFormat}
+	sort.Strings(formats)
+	return formats
+}
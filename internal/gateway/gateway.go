@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Gateway keeps the nginx configuration in ConfigDir in line with the
+// automations, so the hub can route on its own when no external consumer is
+// deployed. Every replica runs it for its own proxy, independently of the
+// event relay: it watches the outbox for changes and retries failed syncs by
+// itself, so a proxy that is down never holds back event delivery.
+type Gateway struct {
+	mu             sync.Mutex
+	repo           automation.Repository
+	outbox         outbox.Repository
+	renderer       *NginxRenderer
+	applier        *applier
+	pollInterval   time.Duration
+	resyncInterval time.Duration
+}
+
+func NewGateway(repo automation.Repository, outboxRepo outbox.Repository, renderer *NginxRenderer, dir string,
+	validator *CommandValidator, reloader *CommandReloader, pollInterval time.Duration,
+	resyncInterval time.Duration) *Gateway {
+	return &Gateway{
+		repo:           repo,
+		outbox:         outboxRepo,
+		renderer:       renderer,
+		applier:        newApplier(dir, validator, reloader),
+		pollInterval:   pollInterval,
+		resyncInterval: resyncInterval,
+	}
+}
+
+var (
+	defaultGateway     *Gateway
+	defaultGatewayOnce sync.Once
+)
+
+// DefaultGateway returns the gateway shared by the sync loop and the API.
+func DefaultGateway() *Gateway {
+	defaultGatewayOnce.Do(func() {
+		reloadCommand := config.AppConfig.GatewayReloadCommand
+		if reloadCommand == "" {
+			reloadCommand = fmt.Sprintf("docker exec %s nginx -s reload", config.AppConfig.NginxContainer)
+		}
+		defaultGateway = NewGateway(automation.DefaultRepository(), outbox.DefaultRepository(),
+			DefaultNginxRenderer(), config.AppConfig.ConfigDir,
+			NewCommandValidator(config.AppConfig.GatewayValidateCommand), NewCommandReloader(reloadCommand),
+			config.AppConfig.GatewayPollInterval, config.AppConfig.GatewayResyncInterval)
+	})
+	return defaultGateway
+}
+
+// Run syncs the configuration whenever a message was added to the outbox
+// since the last successful sync, and keeps retrying a failed sync on every
+// poll. IDs are taken in insertion order but become visible in commit order,
+// so a change committed behind a newer one leaves the newest ID alone; the
+// configuration is therefore also synced every resync interval, which costs
+// no reload when nothing changed. The generation is rebuilt from the
+// repository, which already holds the committed state; renamed automations
+// lose their old file because it is no longer part of the generation.
+func (g *Gateway) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.pollInterval)
+	defer ticker.Stop()
+
+	var (
+		synced     uint64
+		lastSynced time.Time
+	)
+	pending := true
+	for {
+		// read the marker before syncing, so changes committed during the
+		// sync are picked up by the next poll
+		latest, err := g.outbox.LatestID()
+		if err != nil {
+			log.Printf("Failed to check for automation changes: %v", err)
+		} else if pending || latest != synced || time.Since(lastSynced) >= g.resyncInterval {
+			if err := g.Sync(); err != nil {
+				log.Printf("Failed to sync gateway config, retrying: %v", err)
+				pending = true
+			} else {
+				synced, lastSynced, pending = latest, time.Now(), false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync renders every automation and applies the result. A generation that
+// fails validation is reported in the status and not retried until the
+// automations change again; a failed reload is returned so the caller
+// retries.
+func (g *Gateway) Sync() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	automations, err := g.repo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	err = g.applier.apply(g.render(automations))
+	if errors.Is(err, errInvalid) {
+		log.Printf("Gateway config was not applied: %v", err)
+		return nil
+	}
+	return err
+}
+
+func (g *Gateway) Status() Status {
+	return g.applier.status.get()
+}
+
+func (g *Gateway) render(automations []*models.Automation) map[string][]byte {
+	files := make(map[string][]byte, len(automations))
+	for _, route := range NewRoutes(automations) {
+		content, err := g.renderer.Render(route)
+		if err != nil {
+			log.Printf("Failed to render gateway config for automation %s: %v", route.ID, err)
+			continue
+		}
+		upstream, err := g.renderer.RenderUpstream(route)
+		if err != nil {
+			log.Printf("Failed to render gateway upstream for automation %s: %v", route.ID, err)
+			continue
+		}
+		files[FileName(route.Key())] = content
+		if upstream != nil {
+			files[UpstreamFileName(route.Key())] = upstream
+		}
+	}
+	return files
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/config"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Handler struct {
+	gateway *Gateway
+	repo    automation.Repository
+	nginx   *NginxRenderer
+}
+
+// NewHandler builds the gateway handler. gateway may be nil when the built-in
+// gateway is disabled; the config export works either way.
+func NewHandler(gateway *Gateway, repo automation.Repository, nginx *NginxRenderer) *Handler {
+	return &Handler{
+		gateway: gateway,
+		repo:    repo,
+		nginx:   nginx,
+	}
+}
+
+func DefaultHandler() *Handler {
+	var gw *Gateway
+	if config.AppConfig.GatewayEnabled {
+		gw = DefaultGateway()
+	}
+	return NewHandler(gw, automation.DefaultRepository(), DefaultNginxRenderer())
+}
+
+// Config
+// @Summary Export the routing config
+// @Description Render the automations as routing config for nginx, Traefik, Caddy or Envoy, e.g. for Traefik's HTTP provider
+// @Tags Gateway
+// @Produce  plain
+// @Param format query string false "Config format (nginx, traefik, traefik-toml, caddyfile, caddy-json, envoy, envoy-clusters)"
+// @Success 200 {string} string "Routing config"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /gateway/config [get]
+func (h *Handler) Config(c *gin.Context) {
+	exporter, err := NewExporter(c.DefaultQuery("format", NginxFormat), h.nginx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	automations, err := h.repo.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	content, err := exporter.Export(NewRoutes(automations))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, exporter.ContentType(), content)
+}
+
+// Status
+// @Summary Get the gateway config status
+// @Description Report the outcome of the latest gateway config apply, including validation and reload failures
+// @Tags Gateway
+// @Produce  json
+// @Success 200 {object} gateway.Status "Gateway config status"
+// @Router /gateway/status [get]
+func (h *Handler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.gateway.Status())
+}
+
+// Sync
+// @Summary Regenerate the gateway config
+// @Description Render every automation and apply the result to the gateway
+// @Tags Gateway
+// @Produce  json
+// @Success 200 {object} gateway.Status "Gateway config status"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /gateway/sync [post]
+func (h *Handler) Sync(c *gin.Context) {
+	if err := h.gateway.Sync(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.gateway.Status())
+}
@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// maintenanceEscaper encodes, on top of the HTML special characters,
+// everything a proxy could read as a quote, escape, variable or placeholder,
+// so that the page can be embedded verbatim in a quoted config string.
+var maintenanceEscaper = strings.NewReplacer(
+	"$", "&#36;",
+	`\`, "&#92;",
+	"{", "&#123;",
+	"}", "&#125;",
+	"`", "&#96;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+// maintenancePage is the page served in place of an automation that is in
+// maintenance. It is a single line of self-contained HTML.
+func maintenancePage(automation *models.Automation) string {
+	var page strings.Builder
+	page.WriteString(`<!DOCTYPE html><html><head><meta charset=utf-8><title>`)
+	page.WriteString(escapeMaintenance(automation.Name))
+	page.WriteString(` - Maintenance</title></head><body><h1>`)
+	page.WriteString(escapeMaintenance(automation.Name))
+	page.WriteString(` is under maintenance</h1>`)
+	if automation.StateMessage != "" {
+		page.WriteString("<p>" + escapeMaintenance(automation.StateMessage) + "</p>")
+	}
+	if automation.StateETA != nil {
+		page.WriteString("<p>Expected back by " + escapeMaintenance(retryAfter(automation)) + ".</p>")
+	}
+	page.WriteString("</body></html>")
+	return page.String()
+}
+
+func escapeMaintenance(text string) string {
+	return maintenanceEscaper.Replace(html.EscapeString(text))
+}
+
+// retryAfter is the ETA of the automation as a Retry-After value, or empty
+// when no ETA is known.
+func retryAfter(automation *models.Automation) string {
+	if automation.StateETA == nil {
+		return ""
+	}
+	return automation.StateETA.UTC().Format(http.TimeFormat)
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/config"
+	"bytes"
+	"embed"
+	"log"
+	"os"
+	"text/template"
+)
+
+//go:embed templates/location.conf.tmpl templates/upstream.conf.tmpl
+var templates embed.FS
+
+// NginxRenderer renders one nginx location block per route, plus an upstream
+// block for routes with several upstreams. Upstream blocks belong in the http
+// context, so they are kept apart from the location blocks.
+type NginxRenderer struct {
+	tmpl     *template.Template
+	upstream *template.Template
+}
+
+// NewNginxRenderer parses the template at path, or the built-in one when path
+// is empty.
+func NewNginxRenderer(path string) (*NginxRenderer, error) {
+	var (
+		source []byte
+		err    error
+	)
+	if path == "" {
+		source, err = templates.ReadFile("templates/location.conf.tmpl")
+	} else {
+		source, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("location").Parse(string(source))
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := template.ParseFS(templates, "templates/upstream.conf.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &NginxRenderer{tmpl: tmpl, upstream: upstream}, nil
+}
+
+func DefaultNginxRenderer() *NginxRenderer {
+	renderer, err := NewNginxRenderer(config.AppConfig.GatewayTemplate)
+	if err != nil {
+		log.Fatalf("Failed to load gateway template: %v", err)
+	}
+	return renderer
+}
+
+func (r *NginxRenderer) Render(route *Route) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, route); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderUpstream renders the upstream block of the route, or nil when the
+// route proxies to a single upstream or does not proxy at all.
+func (r *NginxRenderer) RenderUpstream(route *Route) ([]byte, error) {
+	if !route.Balanced() || route.Maintenance || route.Redirect != "" {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := r.upstream.Execute(&buf, route); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *NginxRenderer) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+// Export concatenates the upstream blocks and then the location blocks of all
+// routes.
+func (r *NginxRenderer) Export(routes []*Route) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, route := range routes {
+		content, err := r.RenderUpstream(route)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			buf.Write(content)
+			buf.WriteString("\n")
+		}
+	}
+	for _, route := range routes {
+		content, err := r.Render(route)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(content)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
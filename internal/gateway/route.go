@@ -0,0 +1,301 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	hostPattern    = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	urlPathPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+// Route is the proxy-neutral description of how one automation is exposed.
+// Every config format is rendered from routes.
+type Route struct {
+	ID string
+	// Name is for display only. It is not written into proxy configs, where
+	// the ID and Key identify the route.
+	Name string
+	// Path is the URL path of the automation, below the slug of its workspace
+	// unless it is in the default workspace.
+	Path            string
+	Policy          string
+	Upstreams       []Upstream
+	Websocket       bool
+	ConnectTimeout  int
+	ReadTimeout     int
+	MaxBodySize     int
+	PreservePath    bool
+	RequestHeaders  []Header
+	ResponseHeaders []Header
+	// Tags let custom templates apply policies per tag, e.g. with
+	// {{ if .HasTag "internal" }}.
+	Tags []string
+	// Maintenance routes answer every request with 503 and MaintenancePage
+	// instead of proxying. The page is HTML encoded so that it can be put in
+	// a quoted config string as is. RetryAfter is the expected end of the
+	// maintenance as an HTTP date, when known.
+	Maintenance     bool
+	MaintenancePage string
+	RetryAfter      string
+	// Redirect routes answer with a permanent redirect to the same request
+	// below this prefix instead of proxying. They serve the URL paths an
+	// automation had before it was renamed.
+	Redirect string
+}
+
+// Header is an extra header set on proxied requests or responses. Headers are
+// kept sorted so that generated configs are stable.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Upstream is one server of a route. The first upstream is the host and port
+// of the automation itself.
+type Upstream struct {
+	Host   string
+	Port   int
+	Weight int
+	Backup bool
+}
+
+func (u Upstream) Address() string {
+	return u.Host + ":" + strconv.Itoa(u.Port)
+}
+
+// ServiceName is the identifier used for the route in proxy configs.
+func (r *Route) ServiceName() string {
+	return "automation-" + r.Key()
+}
+
+// Key identifies the route in names and file names, where the path cannot
+// be used as is. Slugs and URL paths have no dots, and NewRoutes returns
+// every path once, so keys are unique.
+func (r *Route) Key() string {
+	return strings.ReplaceAll(r.Path, "/", ".")
+}
+
+// HasTag reports whether the automation of the route carries the tag.
+func (r *Route) HasTag(name string) bool {
+	for _, tag := range r.Tags {
+		if tag == name {
+			return true
+		}
+	}
+	return false
+}
+
+func NewRoute(automation *models.Automation) (*Route, error) {
+	// Values end up verbatim in proxy configs, so anything that could break
+	// out of a directive is refused.
+	if !urlPathPattern.MatchString(automation.URLPath) {
+		return nil, fmt.Errorf("urlPath %q cannot be used in a gateway config", automation.URLPath)
+	}
+	return newRoute(automation, automation.URLPath)
+}
+
+// NewEnvironmentRoute exposes the deployment of an automation in one of its
+// environments at /<environment>/<urlPath>.
+func NewEnvironmentRoute(automation *models.Automation, environment *models.AutomationEnvironment) (*Route, error) {
+	if !urlPathPattern.MatchString(automation.URLPath) {
+		return nil, fmt.Errorf("urlPath %q cannot be used in a gateway config", automation.URLPath)
+	}
+	if !urlPathPattern.MatchString(environment.Name) {
+		return nil, fmt.Errorf("environment %q cannot be used in a gateway config", environment.Name)
+	}
+	route, err := newRoute(automation.ForEnvironment(environment), environment.Name+"/"+automation.URLPath)
+	if err != nil {
+		return nil, err
+	}
+	route.ID += "/" + environment.Name
+	route.Name += " (" + environment.Name + ")"
+	return route, nil
+}
+
+func newRoute(automation *models.Automation, path string) (*Route, error) {
+	if !hostPattern.MatchString(automation.Host) {
+		return nil, fmt.Errorf("host %q cannot be used in a gateway config", automation.Host)
+	}
+
+	if ws := automation.WorkspaceSlug(); ws != "" && ws != models.DefaultWorkspaceSlug {
+		path = ws + "/" + path
+	}
+	route := &Route{
+		ID:   automation.ID.String(),
+		Name: automation.Name,
+		Path: path,
+		Upstreams: []Upstream{
+			{Host: automation.Host, Port: automation.Port, Weight: 1},
+		},
+		Policy: automation.LoadBalancing,
+		Tags:   automation.TagNames(),
+	}
+	if route.Policy == "" {
+		route.Policy = models.RoundRobinPolicy
+	}
+	if !models.ValidLoadBalancingPolicy(route.Policy) {
+		return nil, fmt.Errorf("unknown loadBalancing policy %q", route.Policy)
+	}
+	for _, target := range automation.Targets {
+		if !hostPattern.MatchString(target.Host) {
+			return nil, fmt.Errorf("target host %q cannot be used in a gateway config", target.Host)
+		}
+		if err := target.Validate(); err != nil {
+			return nil, err
+		}
+		if target.Backup && route.Policy == models.IPHashPolicy {
+			return nil, fmt.Errorf("backup targets cannot be used with the %s policy", models.IPHashPolicy)
+		}
+		// With the primary/backup policy the automation's own host takes all
+		// traffic and the targets only step in when it is down.
+		route.Upstreams = append(route.Upstreams, Upstream{
+			Host:   target.Host,
+			Port:   target.Port,
+			Weight: target.EffectiveWeight(),
+			Backup: target.Backup || route.Policy == models.PrimaryBackupPolicy,
+		})
+	}
+	if options := automation.Routing; options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		route.Websocket = options.Websocket
+		route.ConnectTimeout = options.ConnectTimeout
+		route.ReadTimeout = options.ReadTimeout
+		route.MaxBodySize = options.MaxBodySize
+		route.PreservePath = options.PreservePath()
+		route.RequestHeaders = sortedHeaders(options.RequestHeaders)
+		route.ResponseHeaders = sortedHeaders(options.ResponseHeaders)
+	}
+	if automation.InMaintenance() {
+		route.Maintenance = true
+		route.MaintenancePage = maintenancePage(automation)
+		route.RetryAfter = retryAfter(automation)
+	}
+	return route, nil
+}
+
+// Balanced reports whether the route spreads requests over several upstreams.
+func (r *Route) Balanced() bool {
+	return len(r.Upstreams) > 1
+}
+
+// Primaries returns the upstreams that receive traffic while all is well.
+func (r *Route) Primaries() []Upstream {
+	return r.filterUpstreams(false)
+}
+
+// Backups returns the upstreams that are only used when the primaries fail.
+func (r *Route) Backups() []Upstream {
+	return r.filterUpstreams(true)
+}
+
+func (r *Route) filterUpstreams(backup bool) []Upstream {
+	var upstreams []Upstream
+	for _, upstream := range r.Upstreams {
+		if upstream.Backup == backup {
+			upstreams = append(upstreams, upstream)
+		}
+	}
+	return upstreams
+}
+
+// Prefix is the path prefix the route matches, without trailing slash.
+func (r *Route) Prefix() string {
+	return "/" + r.Path
+}
+
+func sortedHeaders(headers map[string]string) []Header {
+	sorted := make([]Header, 0, len(headers))
+	for name, value := range headers {
+		sorted = append(sorted, Header{Name: name, Value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// NewRoutes builds the routes of every automation and environment that can
+// be exposed, followed by the redirects from their old URL paths. The others
+// are logged and left out, since retrying cannot fix them and they must not
+// hold back the rest. Disabled automations are not exposed at all.
+func NewRoutes(automations []*models.Automation) []*Route {
+	routes := make([]*Route, 0, len(automations))
+	var redirects []*Route
+	for _, automation := range automations {
+		if automation.Disabled() {
+			continue
+		}
+		route, err := NewRoute(automation)
+		if err != nil {
+			log.Printf("Skipping automation %s in gateway config: %v", automation.ID, err)
+			continue
+		}
+		targets := []*Route{route}
+
+		for _, environment := range automation.Environments {
+			route, err := NewEnvironmentRoute(automation, environment)
+			if err != nil {
+				log.Printf("Skipping environment %s of automation %s in gateway config: %v", environment.Name,
+					automation.ID, err)
+				continue
+			}
+			targets = append(targets, route)
+		}
+		routes = append(routes, targets...)
+		redirects = append(redirects, newRedirectRoutes(automation, targets)...)
+	}
+	// redirects come last, so they never take a path an automation serves
+	return uniqueRoutes(append(routes, redirects...))
+}
+
+// uniqueRoutes keeps the first route of every path. Workspace slugs and
+// environment names are kept apart when they are created, but a clash that
+// slips through, such as environment staging of automation invoice next to
+// automation invoice of workspace staging, must not let one route overwrite
+// the other's files and names.
+func uniqueRoutes(routes []*Route) []*Route {
+	unique := routes[:0]
+	seen := make(map[string]*Route, len(routes))
+	for _, route := range routes {
+		if first, ok := seen[route.Path]; ok {
+			log.Printf("Skipping route %s in gateway config: path /%s is already taken by %s", route.ID, route.Path,
+				first.ID)
+			continue
+		}
+		seen[route.Path] = route
+		unique = append(unique, route)
+	}
+	return unique
+}
+
+// newRedirectRoutes sends every URL path the automation had before a rename,
+// in its main deployment and in each environment, to the route now serving
+// it.
+func newRedirectRoutes(automation *models.Automation, targets []*Route) []*Route {
+	var routes []*Route
+	for _, redirect := range automation.Redirects {
+		if !urlPathPattern.MatchString(redirect.URLPath) {
+			log.Printf("Skipping redirect from %q of automation %s in gateway config", redirect.URLPath,
+				automation.ID)
+			continue
+		}
+		for _, target := range targets {
+			route := *target
+			route.Name += " (redirect)"
+			route.Path = strings.TrimSuffix(target.Path, automation.URLPath) + redirect.URLPath
+			route.Redirect = target.Prefix()
+			route.Maintenance, route.MaintenancePage, route.RetryAfter = false, "", ""
+			routes = append(routes, &route)
+		}
+	}
+	return routes
+}
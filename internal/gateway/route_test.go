@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUniqueRoutesKeepsFirstRouteOfPath(t *testing.T) {
+	routes := uniqueRoutes([]*Route{
+		{ID: "invoice-staging", Path: "staging/invoice"},
+		{ID: "billing", Path: "billing"},
+		{ID: "staging-invoice", Path: "staging/invoice"},
+	})
+
+	if len(routes) != 2 {
+		t.Fatalf("len(routes) = %d, want 2", len(routes))
+	}
+	if routes[0].ID != "invoice-staging" || routes[1].ID != "billing" {
+		t.Errorf("routes = %s, %s, want invoice-staging, billing", routes[0].ID, routes[1].ID)
+	}
+}
+
+func TestRedirectDoesNotShadowRoutesBelowItsPath(t *testing.T) {
+	// reports was called staging before it was renamed
+	reports := &models.Automation{ID: uuid.New(), Name: "Reports", URLPath: "reports", Host: "reports", Port: 8080,
+		Redirects: []*models.URLPathRedirect{{URLPath: "staging"}}}
+	invoice := &models.Automation{ID: uuid.New(), Name: "Invoice", URLPath: "invoice", Host: "invoice", Port: 8080,
+		Environments: []*models.AutomationEnvironment{{Name: "staging", Host: "invoice-staging", Port: 8080}}}
+
+	routes := NewRoutes([]*models.Automation{reports, invoice})
+	paths := make([]string, len(routes))
+	for i, route := range routes {
+		paths[i] = route.Path
+	}
+	if got := strings.Join(paths, ","); got != "reports,invoice,staging/invoice,staging" {
+		t.Fatalf("route paths = %s, want reports,invoice,staging/invoice,staging", got)
+	}
+
+	renderer, err := NewNginxRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := renderer.Export(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"location /staging/invoice/ {",
+		"location = /staging {\n    return 308 /reports$is_args$args;",
+		"location ^~ /staging/ {",
+	} {
+		if !strings.Contains(string(config), want) {
+			t.Errorf("config does not contain %q:\n%s", want, config)
+		}
+	}
+	// nginx checks top-level regex locations before the longest prefix, so
+	// one would take /staging/invoice/ away from the environment
+	if strings.Contains(string(config), "\nlocation ~") {
+		t.Errorf("config has a top-level regex location:\n%s", config)
+	}
+}
@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusApplied    = "applied"
+	StatusInvalid    = "invalid"
+	StatusRolledBack = "rolled_back"
+)
+
+// Status describes the outcome of the latest apply attempt. Generation counts
+// the generations successfully applied since the hub started.
+type Status struct {
+	State         string     `json:"state"`
+	Generation    int        `json:"generation"`
+	Files         int        `json:"files"`
+	AppliedAt     *time.Time `json:"appliedAt,omitempty"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+type statusRecorder struct {
+	mu     sync.RWMutex
+	status Status
+}
+
+func newStatusRecorder() *statusRecorder {
+	return &statusRecorder{status: Status{State: StatusPending}}
+}
+
+func (r *statusRecorder) get() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+func (r *statusRecorder) applied(files int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	r.status.State = StatusApplied
+	r.status.Generation++
+	r.status.Files = files
+	r.status.AppliedAt = &now
+	r.status.LastAttemptAt = &now
+	r.status.Error = ""
+}
+
+func (r *statusRecorder) failed(state string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	r.status.State = state
+	r.status.LastAttemptAt = &now
+	r.status.Error = err.Error()
+}
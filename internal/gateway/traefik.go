@@ -0,0 +1,234 @@
+package gateway
+
+import (
+	"automation-hub-backend/internal/models"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+)
+
+// TraefikExporter renders Traefik dynamic configuration, which Traefik can
+// read from a file or poll from the hub with its HTTP provider. Websockets
+// need no configuration in Traefik. Traefik has no least-connections
+// balancer, so that policy falls back to weighted round-robin, and ip_hash is
+// approximated with a sticky cookie. Traefik cannot answer with a static
+// page either, so routes in maintenance are sent to maintenanceURL, or left
+// out when there is none. Redirects of old paths are answered by Traefik
+// itself through its internal noop service.
+type TraefikExporter struct {
+	toml           bool
+	maintenanceURL string
+}
+
+type traefikConfig struct {
+	HTTP traefikHTTP `yaml:"http" toml:"http"`
+}
+
+type traefikHTTP struct {
+	Routers           map[string]traefikRouter          `yaml:"routers" toml:"routers"`
+	Middlewares       map[string]traefikMiddleware      `yaml:"middlewares,omitempty" toml:"middlewares,omitempty"`
+	Services          map[string]traefikService         `yaml:"services" toml:"services"`
+	ServersTransports map[string]traefikServerTransport `yaml:"serversTransports,omitempty" toml:"serversTransports,omitempty"`
+}
+
+type traefikRouter struct {
+	Rule        string   `yaml:"rule" toml:"rule"`
+	Service     string   `yaml:"service" toml:"service"`
+	Middlewares []string `yaml:"middlewares,omitempty" toml:"middlewares,omitempty"`
+}
+
+type traefikMiddleware struct {
+	StripPrefix   *traefikStripPrefix   `yaml:"stripPrefix,omitempty" toml:"stripPrefix,omitempty"`
+	Headers       *traefikHeaders       `yaml:"headers,omitempty" toml:"headers,omitempty"`
+	Buffering     *traefikBuffering     `yaml:"buffering,omitempty" toml:"buffering,omitempty"`
+	RedirectRegex *traefikRedirectRegex `yaml:"redirectRegex,omitempty" toml:"redirectRegex,omitempty"`
+}
+
+type traefikStripPrefix struct {
+	Prefixes []string `yaml:"prefixes" toml:"prefixes"`
+}
+
+type traefikHeaders struct {
+	CustomRequestHeaders  map[string]string `yaml:"customRequestHeaders,omitempty" toml:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `yaml:"customResponseHeaders,omitempty" toml:"customResponseHeaders,omitempty"`
+}
+
+type traefikBuffering struct {
+	MaxRequestBodyBytes int64 `yaml:"maxRequestBodyBytes" toml:"maxRequestBodyBytes"`
+}
+
+type traefikRedirectRegex struct {
+	Regex       string `yaml:"regex" toml:"regex"`
+	Replacement string `yaml:"replacement" toml:"replacement"`
+	Permanent   bool   `yaml:"permanent" toml:"permanent"`
+}
+
+type traefikService struct {
+	LoadBalancer *traefikLoadBalancer `yaml:"loadBalancer,omitempty" toml:"loadBalancer,omitempty"`
+	Failover     *traefikFailover     `yaml:"failover,omitempty" toml:"failover,omitempty"`
+}
+
+type traefikLoadBalancer struct {
+	Servers          []traefikServer `yaml:"servers" toml:"servers"`
+	Sticky           *traefikSticky  `yaml:"sticky,omitempty" toml:"sticky,omitempty"`
+	ServersTransport string          `yaml:"serversTransport,omitempty" toml:"serversTransport,omitempty"`
+}
+
+type traefikServer struct {
+	URL    string `yaml:"url" toml:"url"`
+	Weight int    `yaml:"weight,omitempty" toml:"weight,omitempty"`
+}
+
+type traefikSticky struct {
+	Cookie traefikCookie `yaml:"cookie" toml:"cookie"`
+}
+
+type traefikCookie struct {
+	Name string `yaml:"name" toml:"name"`
+}
+
+// traefikFailover sends requests to Fallback while Service is unhealthy.
+type traefikFailover struct {
+	Service  string `yaml:"service" toml:"service"`
+	Fallback string `yaml:"fallback" toml:"fallback"`
+}
+
+type traefikServerTransport struct {
+	ForwardingTimeouts traefikForwardingTimeouts `yaml:"forwardingTimeouts" toml:"forwardingTimeouts"`
+}
+
+type traefikForwardingTimeouts struct {
+	DialTimeout           string `yaml:"dialTimeout,omitempty" toml:"dialTimeout,omitempty"`
+	ResponseHeaderTimeout string `yaml:"responseHeaderTimeout,omitempty" toml:"responseHeaderTimeout,omitempty"`
+}
+
+func (e *TraefikExporter) ContentType() string {
+	if e.toml {
+		return "application/toml"
+	}
+	return "application/yaml"
+}
+
+func (e *TraefikExporter) Export(routes []*Route) ([]byte, error) {
+	cfg := traefikConfig{HTTP: traefikHTTP{
+		Routers:           make(map[string]traefikRouter, len(routes)),
+		Middlewares:       make(map[string]traefikMiddleware),
+		Services:          make(map[string]traefikService, len(routes)),
+		ServersTransports: make(map[string]traefikServerTransport),
+	}}
+
+	for _, route := range routes {
+		name := route.ServiceName()
+		router := traefikRouter{
+			Rule:    "PathPrefix(`" + route.Prefix() + "/`)",
+			Service: name,
+		}
+		if route.Redirect != "" {
+			router.Service = "noop@internal"
+			router.Middlewares = []string{name + "-redirect"}
+			cfg.HTTP.Routers[name] = router
+			// the request URL matched by Traefik is absolute
+			cfg.HTTP.Middlewares[name+"-redirect"] = traefikMiddleware{RedirectRegex: &traefikRedirectRegex{
+				Regex:       "^([^:]+://[^/]+)?" + regexp.QuoteMeta(route.Prefix()) + "/(.*)$",
+				Replacement: "${1}" + route.Redirect + "/${2}",
+				Permanent:   true,
+			}}
+			continue
+		}
+		if route.Maintenance {
+			if e.maintenanceURL == "" {
+				continue
+			}
+			cfg.HTTP.Routers[name] = router
+			cfg.HTTP.Services[name] = traefikService{LoadBalancer: &traefikLoadBalancer{
+				Servers: []traefikServer{{URL: e.maintenanceURL}},
+			}}
+			continue
+		}
+		if !route.PreservePath {
+			router.Middlewares = append(router.Middlewares, name+"-strip")
+			cfg.HTTP.Middlewares[name+"-strip"] = traefikMiddleware{
+				StripPrefix: &traefikStripPrefix{Prefixes: []string{route.Prefix()}},
+			}
+		}
+		if len(route.RequestHeaders) > 0 || len(route.ResponseHeaders) > 0 {
+			router.Middlewares = append(router.Middlewares, name+"-headers")
+			cfg.HTTP.Middlewares[name+"-headers"] = traefikMiddleware{Headers: &traefikHeaders{
+				CustomRequestHeaders:  headerMap(route.RequestHeaders),
+				CustomResponseHeaders: headerMap(route.ResponseHeaders),
+			}}
+		}
+		if route.MaxBodySize > 0 {
+			router.Middlewares = append(router.Middlewares, name+"-buffering")
+			cfg.HTTP.Middlewares[name+"-buffering"] = traefikMiddleware{
+				Buffering: &traefikBuffering{MaxRequestBodyBytes: int64(route.MaxBodySize) * 1024 * 1024},
+			}
+		}
+		cfg.HTTP.Routers[name] = router
+
+		transport := ""
+		if route.ConnectTimeout > 0 || route.ReadTimeout > 0 {
+			transport = name
+			cfg.HTTP.ServersTransports[name] = traefikServerTransport{ForwardingTimeouts: traefikForwardingTimeouts{
+				DialTimeout:           seconds(route.ConnectTimeout),
+				ResponseHeaderTimeout: seconds(route.ReadTimeout),
+			}}
+		}
+
+		backups := route.Backups()
+		if len(backups) == 0 {
+			cfg.HTTP.Services[name] = traefikService{LoadBalancer: traefikServers(route, route.Upstreams, transport)}
+			continue
+		}
+		cfg.HTTP.Services[name] = traefikService{Failover: &traefikFailover{
+			Service:  name + "-primary",
+			Fallback: name + "-backup",
+		}}
+		cfg.HTTP.Services[name+"-primary"] = traefikService{
+			LoadBalancer: traefikServers(route, route.Primaries(), transport),
+		}
+		cfg.HTTP.Services[name+"-backup"] = traefikService{
+			LoadBalancer: traefikServers(route, backups, transport),
+		}
+	}
+
+	if e.toml {
+		return toml.Marshal(cfg)
+	}
+	return yaml.Marshal(cfg)
+}
+
+func traefikServers(route *Route, upstreams []Upstream, transport string) *traefikLoadBalancer {
+	loadBalancer := &traefikLoadBalancer{ServersTransport: transport}
+	for _, upstream := range upstreams {
+		server := traefikServer{URL: "http://" + upstream.Address()}
+		if route.Balanced() && upstream.Weight > 1 {
+			server.Weight = upstream.Weight
+		}
+		loadBalancer.Servers = append(loadBalancer.Servers, server)
+	}
+	if route.Policy == models.IPHashPolicy && route.Balanced() {
+		loadBalancer.Sticky = &traefikSticky{Cookie: traefikCookie{Name: route.ServiceName()}}
+	}
+	return loadBalancer
+}
+
+func headerMap(headers []Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(headers))
+	for _, header := range headers {
+		m[header.Name] = header.Value
+	}
+	return m
+}
+
+// seconds formats a timeout as a duration string, or returns "" when unset.
+func seconds(value int) string {
+	if value <= 0 {
+		return ""
+	}
+	return strconv.Itoa(value) + "s"
+}
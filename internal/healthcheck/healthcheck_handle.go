@@ -0,0 +1,129 @@
+package healthcheck
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// Get
+// @Summary Get the health check of an automation
+// @Description Retrieve the health check definition of an automation, or the default TCP check
+// @Tags Health checks
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {object} models.HealthCheck "Successfully retrieved health check"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/healthcheck [get]
+func (h *Handler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	check, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// Set
+// @Summary Define the health check of an automation
+// @Description Create or replace the health check definition of an automation
+// @Tags Health checks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param check body models.HealthCheck true "Health check definition"
+// @Success 200 {object} models.HealthCheck "Successfully saved health check"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/healthcheck [put]
+func (h *Handler) Set(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var check models.HealthCheck
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if err := models.JSON.Unmarshal(body, &check); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved, err := h.service.Set(c.Request.Context(), id, &check)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// Delete
+// @Summary Remove the health check of an automation
+// @Description Remove the health check definition so the default TCP check is used
+// @Tags Health checks
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 204 "Successfully deleted health check"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/healthcheck [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidCheck):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,120 @@
+package healthcheck
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// proberLockKey is the Postgres advisory lock held by the replica that
+// probes, so that every automation is probed and reported by one replica.
+const proberLockKey int64 = 7_310_005
+
+type Repository interface {
+	FindByAutomation(automationID uuid.UUID) (*models.HealthCheck, error)
+	Save(check *models.HealthCheck) (*models.HealthCheck, error)
+	Delete(automationID uuid.UUID) error
+	FindProbeTargets() ([]*models.Automation, error)
+	SaveStatus(status *models.HealthStatus) error
+	CountByStatus() (map[string]int, error)
+	WithProberLock(ctx context.Context, fn func(ctx context.Context)) (bool, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) FindByAutomation(automationID uuid.UUID) (*models.HealthCheck, error) {
+	var check models.HealthCheck
+	err := r.DB.First(&check, "automation_id = ?", automationID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// Save creates or replaces the check of the automation.
+func (r *GormRepository) Save(check *models.HealthCheck) (*models.HealthCheck, error) {
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "automation_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"type", "path", "expected_status", "interval", "timeout",
+			"healthy_threshold", "unhealthy_threshold"}),
+	}).Create(check).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByAutomation(check.AutomationID)
+}
+
+func (r *GormRepository) Delete(automationID uuid.UUID) error {
+	return r.DB.Where("automation_id = ?", automationID).Delete(&models.HealthCheck{}).Error
+}
+
+// FindProbeTargets returns every active automation with its check and last
+// status. Disabled automations and those in maintenance are expected to be
+// down and are not probed, so they raise no alerts.
+func (r *GormRepository) FindProbeTargets() ([]*models.Automation, error) {
+	var automations []*models.Automation
+	err := r.DB.Preload("HealthCheck").Preload("Health").Preload("Tags").
+		Where("state = ?", models.StateActive).Find(&automations).Error
+	if err != nil {
+		return nil, err
+	}
+	return automations, nil
+}
+
+func (r *GormRepository) SaveStatus(status *models.HealthStatus) error {
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(status).Error
+}
+
+// CountByStatus returns the number of automations in every health state.
+// Automations that were never probed count as unknown.
+func (r *GormRepository) CountByStatus() (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	err := r.DB.Table("automations").
+		Select("COALESCE(health_statuses.status, ?) AS status, COUNT(*) AS count", models.HealthUnknown).
+		Joins("LEFT JOIN health_statuses ON health_statuses.automation_id = automations.id").
+		Where("automations.deleted_at IS NULL").
+		Group("1").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{
+		models.HealthHealthy:   0,
+		models.HealthUnhealthy: 0,
+		models.HealthUnknown:   0,
+	}
+	for _, row := range rows {
+		counts[row.Status] += row.Count
+	}
+	return counts, nil
+}
+
+// WithProberLock runs fn holding the prober advisory lock as a lease, until
+// ctx ends or the lease is lost. It reports false without calling fn when
+// another replica holds the lock.
+func (r *GormRepository) WithProberLock(ctx context.Context, fn func(ctx context.Context)) (bool, error) {
+	return infra.WithSessionLease(ctx, r.DB, proberLockKey, fn)
+}
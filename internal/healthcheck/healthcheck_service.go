@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrInvalidCheck       = errors.New("invalid health check")
+)
+
+type Service interface {
+	Get(ctx context.Context, automationID uuid.UUID) (*models.HealthCheck, error)
+	Set(ctx context.Context, automationID uuid.UUID, check *models.HealthCheck) (*models.HealthCheck, error)
+	Delete(ctx context.Context, automationID uuid.UUID) error
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+}
+
+func NewService(repo Repository, automationRepo automation.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository())
+}
+
+// Get returns the check of the automation, or the default check when none
+// is defined.
+func (s *service) Get(ctx context.Context, automationID uuid.UUID) (*models.HealthCheck, error) {
+	if err := s.ensureAutomation(automationID); err != nil {
+		return nil, err
+	}
+
+	check, err := s.repo.FindByAutomation(automationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultHealthCheck(automationID), nil
+	}
+	return check, err
+}
+
+func (s *service) Set(ctx context.Context, automationID uuid.UUID, check *models.HealthCheck) (*models.HealthCheck, error) {
+	if err := s.ensureAutomation(automationID); err != nil {
+		return nil, err
+	}
+
+	check.ID = uuid.UUID{} // reset ID
+	check.AutomationID = automationID
+	check.ApplyDefaults()
+	if err := check.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCheck, err)
+	}
+	return s.repo.Save(check)
+}
+
+// Delete removes the check of the automation, which falls back to the
+// default check.
+func (s *service) Delete(ctx context.Context, automationID uuid.UUID) error {
+	if err := s.ensureAutomation(automationID); err != nil {
+		return err
+	}
+	return s.repo.Delete(automationID)
+}
+
+func (s *service) ensureAutomation(id uuid.UUID) error {
+	_, err := s.automationRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAutomationNotFound
+	}
+	return err
+}
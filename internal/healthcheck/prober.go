@@ -0,0 +1,330 @@
+package healthcheck
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// scheduleTick is how often the prober looks for checks that are due.
+const scheduleTick = time.Second
+
+// leaseRetryInterval is how often a replica that does not probe tries to
+// take over.
+const leaseRetryInterval = 5 * time.Second
+
+// Prober checks the upstream of every automation on the interval of its
+// check. Due checks are handed to a fixed number of workers, so a slow or
+// hanging upstream never holds up more than one worker. Only the replica
+// holding the prober lock probes, so that failure counts, samples, outages
+// and notifications are not multiplied by the number of replicas.
+type Prober struct {
+	repo      Repository
+	workers   int
+	client    *http.Client
+	observers []Observer
+
+	mu    sync.Mutex
+	state map[uuid.UUID]*probeState
+}
+
+// probeState is the in-memory bookkeeping of one automation.
+type probeState struct {
+	check    models.HealthCheck
+	status   models.HealthStatus
+	nextRun  time.Time
+	inFlight bool
+}
+
+// Result is the outcome of one probe together with the status it led to.
+type Result struct {
+	Automation *models.Automation
+	Check      models.HealthCheck
+	CheckedAt  time.Time
+	Latency    time.Duration
+	Err        error
+	Previous   string
+	Status     models.HealthStatus
+}
+
+// Since estimates when the current streak of outcomes began from the number
+// of consecutive results and the check interval.
+func (r *Result) Since() time.Time {
+	streak := r.Status.ConsecutiveSuccesses
+	if r.Err != nil {
+		streak = r.Status.ConsecutiveFailures
+	}
+	if streak < 1 {
+		streak = 1
+	}
+	return r.CheckedAt.Add(-time.Duration(streak-1) * r.Check.IntervalDuration())
+}
+
+// Changed reports whether the probe flipped the status of the automation.
+func (r *Result) Changed() bool {
+	return r.Previous != r.Status.Status
+}
+
+// Observer is told about every probe once its status has been saved.
+type Observer interface {
+	Observe(result *Result) error
+}
+
+type probeJob struct {
+	automation *models.Automation
+	check      models.HealthCheck
+}
+
+func NewProber(repo Repository, workers int) *Prober {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Prober{
+		repo:    repo,
+		workers: workers,
+		client: &http.Client{
+			// the upstream itself must answer, not whatever it redirects to
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		state: make(map[uuid.UUID]*probeState),
+	}
+}
+
+func DefaultProber() *Prober {
+	return NewProber(DefaultRepository(), config.AppConfig.HealthCheckWorkers)
+}
+
+// AddObserver makes the prober report every probe result to observer.
+func (p *Prober) AddObserver(observer Observer) {
+	p.observers = append(p.observers, observer)
+}
+
+// Run probes while this replica holds the prober lock, and otherwise waits
+// to take over, until ctx ends.
+func (p *Prober) Run(ctx context.Context) {
+	for {
+		if _, err := p.repo.WithProberLock(ctx, p.probeAll); err != nil {
+			log.Printf("Stopped probing: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(leaseRetryInterval):
+		}
+	}
+}
+
+// probeAll probes until ctx ends. It starts from the statuses saved in the
+// database, since another replica may have probed meanwhile.
+func (p *Prober) probeAll(ctx context.Context) {
+	p.mu.Lock()
+	p.state = make(map[uuid.UUID]*probeState)
+	p.mu.Unlock()
+
+	jobs := make(chan probeJob)
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				p.probe(ctx, job)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+	for {
+		p.schedule(ctx, jobs)
+
+		select {
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// schedule queues the checks that are due. It blocks while every worker is
+// busy, which delays the next round instead of piling up probes.
+func (p *Prober) schedule(ctx context.Context, jobs chan<- probeJob) {
+	automations, err := p.repo.FindProbeTargets()
+	if err != nil {
+		log.Printf("Failed to load health check targets: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var due []probeJob
+	p.mu.Lock()
+	seen := make(map[uuid.UUID]bool, len(automations))
+	for _, automation := range automations {
+		seen[automation.ID] = true
+
+		check := models.DefaultHealthCheck(automation.ID)
+		if automation.HealthCheck != nil {
+			check = automation.HealthCheck
+			check.ApplyDefaults()
+		}
+
+		state, ok := p.state[automation.ID]
+		if !ok {
+			state = &probeState{status: models.HealthStatus{AutomationID: automation.ID, Status: models.HealthUnknown}}
+			if automation.Health != nil {
+				state.status = *automation.Health
+			}
+			p.state[automation.ID] = state
+		}
+		if state.check != *check {
+			// a new definition starts counting from scratch
+			state.check = *check
+			state.status.ConsecutiveSuccesses = 0
+			state.status.ConsecutiveFailures = 0
+			state.nextRun = now
+		}
+		if state.inFlight || now.Before(state.nextRun) {
+			continue
+		}
+		state.inFlight = true
+		state.nextRun = now.Add(check.IntervalDuration())
+		due = append(due, probeJob{automation: automation, check: *check})
+	}
+	for id, state := range p.state {
+		if !seen[id] && !state.inFlight {
+			delete(p.state, id)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, job := range due {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Prober) probe(ctx context.Context, job probeJob) {
+	start := time.Now()
+	err := p.check(ctx, job.automation, &job.check)
+	latency := time.Since(start)
+	if ctx.Err() != nil {
+		// cut short by shutdown or a lost lease, which says nothing about
+		// the upstream
+		return
+	}
+
+	p.mu.Lock()
+	state, ok := p.state[job.automation.ID]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	state.inFlight = false
+	if state.check != job.check {
+		// the definition changed while probing
+		p.mu.Unlock()
+		return
+	}
+	previous := state.status.Status
+	record(&state.status, &job.check, err, latency, start.UTC())
+	result := &Result{
+		Automation: job.automation,
+		Check:      job.check,
+		CheckedAt:  start.UTC(),
+		Latency:    latency,
+		Err:        err,
+		Previous:   previous,
+		Status:     state.status,
+	}
+	p.mu.Unlock()
+
+	if errSave := p.repo.SaveStatus(&result.Status); errSave != nil {
+		log.Printf("Failed to save health status of automation %s: %v", job.automation.ID, errSave)
+		return
+	}
+	for _, observer := range p.observers {
+		if errObserve := observer.Observe(result); errObserve != nil {
+			log.Printf("Failed to process health result of automation %s: %v", job.automation.ID, errObserve)
+		}
+	}
+}
+
+// record applies the outcome of a probe to the status. The status only flips
+// once the threshold of consecutive outcomes is reached.
+func record(status *models.HealthStatus, check *models.HealthCheck, err error, latency time.Duration, at time.Time) {
+	status.LastCheckedAt = &at
+	status.LatencyMs = latency.Milliseconds()
+
+	next := status.Status
+	if err == nil {
+		status.ConsecutiveSuccesses++
+		status.ConsecutiveFailures = 0
+		status.LastError = ""
+		if status.ConsecutiveSuccesses >= check.HealthyThreshold {
+			next = models.HealthHealthy
+		}
+	} else {
+		status.ConsecutiveFailures++
+		status.ConsecutiveSuccesses = 0
+		status.LastError = err.Error()
+		if status.ConsecutiveFailures >= check.UnhealthyThreshold {
+			next = models.HealthUnhealthy
+		}
+	}
+	if next != status.Status {
+		status.Status = next
+		status.LastChangedAt = &at
+	}
+}
+
+func (p *Prober) check(ctx context.Context, automation *models.Automation, check *models.HealthCheck) error {
+	address := net.JoinHostPort(automation.Host, strconv.Itoa(automation.Port))
+	ctx, cancel := context.WithTimeout(ctx, check.TimeoutDuration())
+	defer cancel()
+
+	if check.Type == models.HTTPCheck {
+		return p.checkHTTP(ctx, "http://"+address+check.Path, check.ExpectedStatus)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p *Prober) checkHTTP(ctx context.Context, url string, expectedStatus int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "automation-hub-healthcheck")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("expected status %d, got %d", expectedStatus, resp.StatusCode)
+	}
+	return nil
+}
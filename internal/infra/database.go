@@ -2,10 +2,35 @@ package infra
 
 import (
 	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/metrics"
 	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/tracing"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dbRetryInterval is how long WaitForDefaultDB waits between attempts.
+const dbRetryInterval = 5 * time.Second
+
+// leaseCheckInterval is how often WithSessionLease checks that the
+// connection holding its lock is still alive.
+const leaseCheckInterval = 5 * time.Second
+
+var (
+	defaultDB      atomic.Pointer[gorm.DB]
+	defaultDBMu    sync.Mutex
+	defaultDBError atomic.Pointer[error]
+
+	migrating atomic.Bool
 )
 
 func NewPostgresDatabase(user, password, dbName, dbHost string, dbPort int) (*gorm.DB, error) {
@@ -16,11 +41,69 @@ func NewPostgresDatabase(user, password, dbName, dbHost string, dbPort int) (*go
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
 
+// GetDefaultDB returns the connection shared by every default repository. The
+// connection is opened and migrated on first use. A failure is not kept, so
+// the next call tries again.
 func GetDefaultDB() (*gorm.DB, error) {
+	if db := defaultDB.Load(); db != nil {
+		return db, nil
+	}
+
+	defaultDBMu.Lock()
+	defer defaultDBMu.Unlock()
+	if db := defaultDB.Load(); db != nil {
+		return db, nil
+	}
+	db, err := newDefaultDB()
+	if err != nil {
+		defaultDBError.Store(&err)
+		return nil, err
+	}
+	defaultDB.Store(db)
+	return db, nil
+}
+
+// WaitForDefaultDB opens and migrates the shared connection, retrying until
+// the database is reachable or ctx ends.
+func WaitForDefaultDB(ctx context.Context) (*gorm.DB, error) {
+	for {
+		db, err := GetDefaultDB()
+		if err == nil {
+			return db, nil
+		}
+		log.Printf("Failed to open the database, retrying in %s: %v", dbRetryInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(dbRetryInterval):
+		}
+	}
+}
+
+// OpenedDB returns the shared connection without opening it, so that checks
+// do not wait for the startup. It fails while the connection is not open yet.
+func OpenedDB() (*gorm.DB, error) {
+	if db := defaultDB.Load(); db != nil {
+		return db, nil
+	}
+	if err := defaultDBError.Load(); err != nil {
+		return nil, fmt.Errorf("database is not open yet: %w", *err)
+	}
+	return nil, errors.New("database is not open yet")
+}
+
+func newDefaultDB() (*gorm.DB, error) {
 	db, err := NewPostgresDatabase(config.AppConfig.DbUser, config.AppConfig.DbPassword,
 		config.AppConfig.DbName, config.AppConfig.DbHost, config.AppConfig.DbPort)
 	if err != nil {
@@ -34,9 +117,150 @@ func GetDefaultDB() (*gorm.DB, error) {
 	return db, nil
 }
 
+// WithSessionLock runs fn while holding the Postgres advisory lock key on a
+// connection of its own. Unlike a transaction-scoped lock, it keeps no
+// transaction open while fn talks to slow peers such as brokers. The lock is
+// a lease: it ends when fn returns, or with the connection when the process
+// dies. It reports false without calling fn when the lock is held elsewhere.
+func WithSessionLock(db *gorm.DB, key int64, fn func() error) (bool, error) {
+	return withSessionLock(db, key, func(*sql.Conn) error {
+		return fn()
+	})
+}
+
+// WithSessionLease is WithSessionLock for work that runs until ctx ends,
+// such as a loop only one replica may run. The ctx passed to fn is also
+// cancelled when the connection holding the lock fails, since the lock went
+// with it and another replica may take over; fn must return then.
+func WithSessionLease(ctx context.Context, db *gorm.DB, key int64, fn func(ctx context.Context)) (bool, error) {
+	return withSessionLock(db, key, func(conn *sql.Conn) error {
+		leaseCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			fn(leaseCtx)
+		}()
+
+		ticker := time.NewTicker(leaseCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return nil
+			case <-ticker.C:
+				if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+					cancel()
+					<-done
+					return fmt.Errorf("lost advisory lock %d: %w", key, err)
+				}
+			}
+		}
+	})
+}
+
+func withSessionLock(db *gorm.DB, key int64, fn func(conn *sql.Conn) error) (bool, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false, err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	acquired := false
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("Failed to release advisory lock %d, dropping its connection: %v", key, err)
+			// a connection that may still hold the lock must not go back to
+			// the pool
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+	return true, fn(conn)
+}
+
+// MigrationsRunning reports whether RunMigrations is in progress.
+func MigrationsRunning() bool {
+	return migrating.Load()
+}
+
 func RunMigrations(db *gorm.DB) error {
-	if err := db.AutoMigrate(&models.Automation{}); err != nil {
+	migrating.Store(true)
+	defer migrating.Store(false)
+
+	if err := deleteOrphanedHistory(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&models.Workspace{}, &models.Tag{}, &models.Category{}, &models.Automation{}, &models.UpstreamTarget{}, &models.AutomationEnvironment{}, &models.URLPathRedirect{}, &models.HealthCheck{},
+		&models.HealthStatus{}, &models.UptimeSample{}, &models.UptimeRollup{}, &models.Outage{},
+		&models.NotificationChannel{}, &models.NotificationRule{}, &models.NotificationDelivery{},
+		&models.AutomationRevision{}, &models.MaintenanceWindow{}, &models.OutboxMessage{}); err != nil {
 		return err
 	}
+	if err := migrateDefaultWorkspace(db); err != nil {
+		return err
+	}
+	return migrateAutomationUniqueIndexes(db)
+}
+
+// deleteOrphanedHistory removes the uptime history and notification
+// deliveries that earlier versions left behind when purging automations, so
+// that their foreign keys can be added. Once a table has its foreign key
+// there is nothing left to do.
+func deleteOrphanedHistory(db *gorm.DB) error {
+	for _, model := range []interface{}{&models.UptimeSample{}, &models.UptimeRollup{}, &models.Outage{},
+		&models.NotificationDelivery{}} {
+		if !db.Migrator().HasTable(model) || db.Migrator().HasConstraint(model, "Automation") {
+			continue
+		}
+		err := db.Where("NOT EXISTS (SELECT 1 FROM automations WHERE automations.id = automation_id)").
+			Delete(model).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateDefaultWorkspace creates the default workspace and moves the
+// automations of earlier versions, which have none, into it.
+func migrateDefaultWorkspace(db *gorm.DB) error {
+	var workspace models.Workspace
+	err := db.Where(models.Workspace{Slug: models.DefaultWorkspaceSlug}).
+		Attrs(models.Workspace{Name: "Default"}).FirstOrCreate(&workspace).Error
+	if err != nil {
+		return err
+	}
+	return db.Exec("UPDATE automations SET workspace_id = ? WHERE workspace_id IS NULL", workspace.ID).Error
+}
+
+// migrateAutomationUniqueIndexes replaces the unique constraints and indexes
+// of earlier versions with partial unique indexes per workspace that ignore
+// soft-deleted rows. Gorm cannot express these through tags without also
+// adding a plain constraint.
+func migrateAutomationUniqueIndexes(db *gorm.DB) error {
+	for _, column := range models.AutomationUniqueColumns {
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE automations DROP CONSTRAINT IF EXISTS automations_%s_key",
+			column)).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS idx_automations_%s_active", column)).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_automations_%s_workspace "+
+			"ON automations (workspace_id, %s) WHERE deleted_at IS NULL", column, column)).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,147 @@
+package maintenance
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// GetAll
+// @Summary Get the maintenance windows of an automation
+// @Description Retrieve the planned maintenance windows of an automation, the next one first
+// @Tags Maintenance
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {array} models.MaintenanceWindow "Successfully retrieved maintenance windows"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/maintenance-windows [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	windows, err := h.service.FindAll(c.Request.Context(), automationID)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// Create
+// @Summary Plan a maintenance window
+// @Description Put the automation into maintenance from start to end, optionally repeating at every start of a cron expression in UTC until a given time. Recurrences are five-field cron expressions or macros such as @weekly; RRULEs are not supported
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param window body models.MaintenanceWindow true "Maintenance window"
+// @Success 201 {object} models.MaintenanceWindow "Successfully planned maintenance window"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/maintenance-windows [post]
+func (h *Handler) Create(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var window models.MaintenanceWindow
+	if !readBody(c, &window) {
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), automationID, &window)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Delete
+// @Summary Cancel a maintenance window
+// @Description Delete a maintenance window; when it is in progress the automation becomes active again
+// @Tags Maintenance
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param windowId path string true "Maintenance window ID"
+// @Success 204 "Successfully deleted maintenance window"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/maintenance-windows/{windowId} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "windowId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), automationID, id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for " + param})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readBody(c *gin.Context, value interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound), errors.Is(err, ErrWindowNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidWindow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
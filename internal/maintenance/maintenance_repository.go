@@ -0,0 +1,136 @@
+package maintenance
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// schedulerLockKey is the Postgres advisory lock held while a replica moves
+// automations in and out of maintenance, so that every transition happens
+// once.
+const schedulerLockKey int64 = 7_310_003
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindByAutomation(automationID uuid.UUID) ([]*models.MaintenanceWindow, error)
+	Find(automationID uuid.UUID, id uuid.UUID) (*models.MaintenanceWindow, error)
+	FindDueToStart(now time.Time) ([]*models.MaintenanceWindow, error)
+	FindDueToEnd(now time.Time) ([]*models.MaintenanceWindow, error)
+	CountInProgress(automationID uuid.UUID, except uuid.UUID) (int64, error)
+	Save(window *models.MaintenanceWindow) (*models.MaintenanceWindow, error)
+	Delete(id uuid.UUID) error
+	WithSchedulerLock(fn func(tx *gorm.DB) error) (bool, error)
+	LockScheduler() error
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+// FindByAutomation returns the windows of an automation, the ones coming up
+// first and the finished ones last.
+func (r *GormRepository) FindByAutomation(automationID uuid.UUID) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	err := r.DB.Where("automation_id = ?", automationID).
+		Order("next_start asc nulls last").Order("starts_at asc").Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (r *GormRepository) Find(automationID uuid.UUID, id uuid.UUID) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	err := r.DB.First(&window, "automation_id = ? AND id = ?", automationID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// FindDueToStart returns the windows whose next occurrence has begun but
+// was not started yet, including occurrences that were missed entirely.
+func (r *GormRepository) FindDueToStart(now time.Time) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	err := r.DB.Where("NOT in_progress AND next_start <= ?", now).Order("next_start asc").Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// FindDueToEnd returns the windows in progress whose occurrence is over.
+func (r *GormRepository) FindDueToEnd(now time.Time) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	err := r.DB.Where("in_progress AND next_end <= ?", now).Order("next_end asc").Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// CountInProgress counts the windows of an automation that are in progress,
+// leaving out except.
+func (r *GormRepository) CountInProgress(automationID uuid.UUID, except uuid.UUID) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.MaintenanceWindow{}).
+		Where("automation_id = ? AND in_progress AND id <> ?", automationID, except).Count(&count).Error
+	return count, err
+}
+
+// Save creates the window when it has no ID yet and replaces it otherwise.
+func (r *GormRepository) Save(window *models.MaintenanceWindow) (*models.MaintenanceWindow, error) {
+	err := r.DB.Save(window).Error
+	if err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+func (r *GormRepository) Delete(id uuid.UUID) error {
+	return r.DB.Delete(&models.MaintenanceWindow{}, id).Error
+}
+
+// WithSchedulerLock runs fn inside a transaction holding the scheduler
+// advisory lock. It reports false without calling fn when another replica
+// holds the lock.
+func (r *GormRepository) WithSchedulerLock(fn func(tx *gorm.DB) error) (bool, error) {
+	acquired := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", schedulerLockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		return fn(tx)
+	})
+	return acquired, err
+}
+
+// LockScheduler waits for the scheduler advisory lock, keeping the scheduler
+// away from the windows until the surrounding transaction ends.
+func (r *GormRepository) LockScheduler() error {
+	return r.DB.Exec("SELECT pg_advisory_xact_lock(?)", schedulerLockKey).Error
+}
@@ -0,0 +1,131 @@
+package maintenance
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"automation-hub-backend/internal/revision"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrWindowNotFound     = errors.New("maintenance window not found")
+	ErrInvalidWindow      = errors.New("invalid maintenance window")
+)
+
+type Service interface {
+	FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.MaintenanceWindow, error)
+	Create(ctx context.Context, automationID uuid.UUID, window *models.MaintenanceWindow) (*models.MaintenanceWindow, error)
+	Delete(ctx context.Context, automationID uuid.UUID, id uuid.UUID) error
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+	transitions    *transitioner
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository,
+	revisionRepo revision.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+		transitions:    newTransitioner(repo, automationRepo, outboxRepo, revisionRepo),
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository(),
+		revision.DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.MaintenanceWindow, error) {
+	if _, err := findAutomation(s.automationRepo, automationID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByAutomation(automationID)
+}
+
+// Create plans a window. Its first occurrence is the one given by start and
+// end, unless that is already over, in which case a recurring window starts
+// with its next occurrence. The scheduler picks it up from there.
+func (s *service) Create(ctx context.Context, automationID uuid.UUID,
+	window *models.MaintenanceWindow) (*models.MaintenanceWindow, error) {
+	if _, err := findAutomation(s.automationRepo, automationID); err != nil {
+		return nil, err
+	}
+
+	window.ID = uuid.UUID{}
+	window.AutomationID = automationID
+	window.Start = window.Start.UTC()
+	window.End = window.End.UTC()
+	window.CreatedBy = events.ActorFromContext(ctx)
+	if err := window.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWindow, err)
+	}
+	if window.Recurrence != "" {
+		schedule, err := ParseSchedule(window.Recurrence)
+		if err != nil {
+			return nil, fmt.Errorf("%w: recurrence: %v", ErrInvalidWindow, err)
+		}
+		if schedule.Next(window.Start).IsZero() {
+			return nil, fmt.Errorf("%w: recurrence %q never matches", ErrInvalidWindow, window.Recurrence)
+		}
+	}
+
+	now := time.Now().UTC()
+	start, end := window.Start, window.End
+	window.InProgress = false
+	window.NextStart, window.NextEnd = &start, &end
+	if !end.After(now) {
+		if err := advance(window, end, now); err != nil {
+			return nil, err
+		}
+		if window.Finished() {
+			return nil, fmt.Errorf("%w: the window is already over", ErrInvalidWindow)
+		}
+	}
+	return s.repo.Save(window)
+}
+
+// Delete removes a window. When it is in progress the automation comes out
+// of maintenance right away, as if the window had ended.
+func (s *service) Delete(ctx context.Context, automationID uuid.UUID, id uuid.UUID) error {
+	return s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if err := txRepo.LockScheduler(); err != nil {
+			return err
+		}
+		window, err := txRepo.Find(automationID, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWindowNotFound
+			}
+			return err
+		}
+		if window.InProgress {
+			if err := s.transitions.end(ctx, tx, window, time.Now().UTC()); err != nil {
+				return err
+			}
+		}
+		return txRepo.Delete(window.ID)
+	})
+}
+
+func findAutomation(repo automation.Repository, id uuid.UUID) (*models.Automation, error) {
+	current, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+	return current, nil
+}
@@ -0,0 +1,195 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleHorizon bounds the search for the next match of a schedule, so
+// that expressions that never match, such as "0 0 30 2 *", end.
+const scheduleHorizon = 5 * 366 * 24 * time.Hour
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// Schedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week. Times are matched in UTC. As in cron, a day
+// matches when either day field does if both are restricted.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	anyDayOfMonth, anyDayOfWeek                bool
+}
+
+type scheduleField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var scheduleFields = []scheduleField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 7 is Sunday as well
+	{name: "day of week", min: 0, max: 7, names: dayNames},
+}
+
+// ParseSchedule parses a cron expression such as "30 2 * * SUN" or one of
+// the macros @hourly, @daily, @weekly, @monthly and @yearly. Only cron is
+// accepted; iCalendar RRULEs are rejected as such rather than as a cron
+// expression with the wrong number of fields.
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if upper := strings.ToUpper(expr); strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return nil, fmt.Errorf("RRULE is not supported, use a cron expression such as \"0 2 * * SUN\"")
+	}
+	if macro, ok := scheduleMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(scheduleFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expr, len(scheduleFields))
+	}
+
+	sets := make([]uint64, len(parts))
+	for i, part := range parts {
+		set, err := parseScheduleField(part, scheduleFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	// fold Sunday as 7 onto 0
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dayOfMonth:    sets[2],
+		month:         sets[3],
+		dayOfWeek:     sets[4],
+		anyDayOfMonth: unrestricted(parts[2]),
+		anyDayOfWeek:  unrestricted(parts[4]),
+	}, nil
+}
+
+// unrestricted reports whether a day field starts with a wildcard, which is
+// how cron decides whether to combine the day fields.
+func unrestricted(text string) bool {
+	return strings.HasPrefix(text, "*") || strings.HasPrefix(text, "?")
+}
+
+// parseScheduleField parses a comma separated list of *, values, ranges and
+// steps into a bit set.
+func parseScheduleField(text string, field scheduleField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepText, field.name)
+			}
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangeText == "*" || rangeText == "?":
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = field.value(lowText); err != nil {
+				return 0, err
+			}
+			if high, err = field.value(highText); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s", rangeText, field.name)
+			}
+		default:
+			value, err := field.value(rangeText)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			// a single value with a step runs to the end of the field
+			if !hasStep {
+				high = value
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+	return set, nil
+}
+
+func (f scheduleField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return i + f.min, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be between %d and %d", f.name, text, f.min, f.max)
+	}
+	return value, nil
+}
+
+// Next returns the first time after t that matches the schedule, or the zero
+// time when there is none within the next years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(scheduleHorizon)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDayOfMonth && s.anyDayOfWeek:
+		return true
+	case s.anyDayOfMonth:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
@@ -0,0 +1,79 @@
+package maintenance
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleRejectsInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "RRULE:FREQ=WEEKLY;BYDAY=SU", want: "RRULE is not supported"},
+		{expr: "rrule:freq=daily", want: "RRULE is not supported"},
+		{expr: "FREQ=DAILY;BYHOUR=2", want: "RRULE is not supported"},
+		{expr: "", want: "must have 5 fields"},
+		{expr: "* * * *", want: "must have 5 fields"},
+		{expr: "0 2 * * SUN 2024", want: "must have 5 fields"},
+		{expr: "60 * * * *", want: "invalid minute"},
+		{expr: "* 24 * * *", want: "invalid hour"},
+		{expr: "* * 0 * *", want: "invalid day of month"},
+		{expr: "* * * FOO *", want: "invalid month"},
+		{expr: "* * * * 8", want: "invalid day of week"},
+		{expr: "5-1 * * * *", want: "invalid range"},
+		{expr: "*/0 * * * *", want: "invalid step"},
+		{expr: "@fortnightly", want: "must have 5 fields"},
+	}
+	for _, test := range tests {
+		_, err := ParseSchedule(test.expr)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ParseSchedule(%q) error = %v, want %q", test.expr, err, test.want)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{expr: "30 2 * * SUN", want: time.Date(2024, 5, 5, 2, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, 5, 1, 12, 15, 0, 0, time.UTC)},
+		{expr: "0,45 12 * * *", want: time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC)},
+		{expr: "@hourly", want: time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)},
+		{expr: "@Monthly", want: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "  @daily  ", want: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 9 13 * *", want: time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted
+		{expr: "0 0 13 * FRI", want: time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 8 * JAN-MAR MON-FRI", want: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+		// strictly after
+		{expr: "0 12 * * *", from: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)},
+		// matched in UTC
+		{expr: "0 12 * * *", from: time.Date(2024, 5, 1, 13, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			want: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error = %v", test.expr, err)
+			continue
+		}
+		start := test.from
+		if start.IsZero() {
+			start = from
+		}
+		if got := schedule.Next(start); !got.Equal(test.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", test.expr, start, got, test.want)
+		}
+	}
+}
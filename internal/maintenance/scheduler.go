@@ -0,0 +1,222 @@
+package maintenance
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"automation-hub-backend/internal/revision"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// schedulerActor is the actor of the events the scheduler causes.
+const schedulerActor = "maintenance-scheduler"
+
+// Scheduler puts automations into maintenance when one of their windows
+// starts and makes them active again when it ends. Replicas take turns
+// through an advisory lock, and every transition is committed together with
+// its event and the window's progress, so each one fires exactly once.
+type Scheduler struct {
+	repo         Repository
+	transitions  *transitioner
+	pollInterval time.Duration
+}
+
+func NewScheduler(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository,
+	revisionRepo revision.Repository, pollInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		repo:         repo,
+		transitions:  newTransitioner(repo, automationRepo, outboxRepo, revisionRepo),
+		pollInterval: pollInterval,
+	}
+}
+
+func DefaultScheduler() *Scheduler {
+	return NewScheduler(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository(),
+		revision.DefaultRepository(), config.AppConfig.MaintenancePollInterval)
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	ctx = events.WithActor(ctx, schedulerActor)
+	for {
+		if _, err := s.repo.WithSchedulerLock(func(tx *gorm.DB) error {
+			return s.run(ctx, tx, time.Now().UTC())
+		}); err != nil {
+			log.Printf("Failed to run maintenance windows: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run ends the occurrences that are over before starting new ones, so that
+// back-to-back windows hand over without the automation becoming active in
+// between.
+func (s *Scheduler) run(ctx context.Context, tx *gorm.DB, now time.Time) error {
+	repo := s.repo.WithTx(tx)
+	ending, err := repo.FindDueToEnd(now)
+	if err != nil {
+		return err
+	}
+	for _, window := range ending {
+		if err := s.transitions.end(ctx, tx, window, now); err != nil {
+			return err
+		}
+	}
+
+	starting, err := repo.FindDueToStart(now)
+	if err != nil {
+		return err
+	}
+	for _, window := range starting {
+		if err := s.transitions.start(ctx, tx, window, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transitioner moves automations in and out of maintenance for their
+// windows, inside the transaction of the caller.
+type transitioner struct {
+	repo        Repository
+	automations automation.Repository
+	outbox      outbox.Repository
+	revisions   revision.Repository
+}
+
+func newTransitioner(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository,
+	revisionRepo revision.Repository) *transitioner {
+	return &transitioner{
+		repo:        repo,
+		automations: automationRepo,
+		outbox:      outboxRepo,
+		revisions:   revisionRepo,
+	}
+}
+
+// start puts the automation into maintenance until the occurrence ends. An
+// occurrence that was missed entirely, e.g. while no replica was running, is
+// skipped. Disabled automations are left alone.
+func (t *transitioner) start(ctx context.Context, tx *gorm.DB, window *models.MaintenanceWindow,
+	now time.Time) error {
+	if !window.NextEnd.After(now) {
+		log.Printf("Skipping missed occurrence of maintenance window %s at %s", window.ID, window.NextStart)
+		if err := advance(window, *window.NextEnd, now); err != nil {
+			return err
+		}
+		_, err := t.repo.WithTx(tx).Save(window)
+		return err
+	}
+
+	current, err := t.automations.WithTx(tx).FindByID(window.AutomationID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// the automation is in the trash
+	case err != nil:
+		return err
+	case !current.Disabled():
+		eta := *window.NextEnd
+		if err := t.setState(ctx, tx, current, models.Lifecycle{
+			State:        models.StateMaintenance,
+			StateMessage: window.Message,
+			StateETA:     &eta,
+		}, now); err != nil {
+			return err
+		}
+	}
+
+	window.InProgress = true
+	_, err = t.repo.WithTx(tx).Save(window)
+	return err
+}
+
+// end makes the automation active again, unless its state was changed by
+// hand meanwhile or another of its windows is still in progress, and moves
+// the window to its next occurrence.
+func (t *transitioner) end(ctx context.Context, tx *gorm.DB, window *models.MaintenanceWindow,
+	now time.Time) error {
+	current, err := t.automations.WithTx(tx).FindByID(window.AutomationID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// the automation is in the trash
+	case err != nil:
+		return err
+	case current.InMaintenance():
+		others, err := t.repo.WithTx(tx).CountInProgress(window.AutomationID, window.ID)
+		if err != nil {
+			return err
+		}
+		if others == 0 {
+			if err := t.setState(ctx, tx, current, models.Lifecycle{State: models.StateActive}, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := advance(window, *window.NextEnd, now); err != nil {
+		return err
+	}
+	_, err = t.repo.WithTx(tx).Save(window)
+	return err
+}
+
+// setState changes the state of the automation like automation.Service's
+// SetState does, recording the revision and the event in tx.
+func (t *transitioner) setState(ctx context.Context, tx *gorm.DB, current *models.Automation,
+	lifecycle models.Lifecycle, now time.Time) error {
+	before := *current
+	lifecycle.StateChangedAt = &now
+	current.Lifecycle = lifecycle
+	updated, err := t.automations.WithTx(tx).Update(current)
+	if err != nil {
+		return err
+	}
+	if err := t.revisions.WithTx(tx).Record(revision.New(ctx, models.RevisionState, &before,
+		updated)); err != nil {
+		return err
+	}
+	return t.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.StateEventType(lifecycle.State),
+		updated))
+}
+
+// advance moves the window to its first occurrence that starts no earlier
+// than after and has not ended by now. A window without recurrence, or whose
+// recurrence has run out, is finished.
+func advance(window *models.MaintenanceWindow, after time.Time, now time.Time) error {
+	window.InProgress = false
+	window.NextStart, window.NextEnd = nil, nil
+	if window.Recurrence == "" {
+		return nil
+	}
+	schedule, err := ParseSchedule(window.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	// Next returns times strictly after its argument; occurrences may start
+	// right when the previous one ended.
+	from := after.Add(-time.Nanosecond)
+	if unended := now.Add(-window.Duration()); unended.After(from) {
+		from = unended
+	}
+	next := schedule.Next(from)
+	if next.IsZero() || (window.Until != nil && next.After(*window.Until)) {
+		return nil
+	}
+	nextEnd := next.Add(window.Duration())
+	window.NextStart, window.NextEnd = &next, &nextEnd
+	return nil
+}
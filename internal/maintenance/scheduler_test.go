@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"automation-hub-backend/internal/models"
+	"testing"
+	"time"
+)
+
+func date(day int, hour int) time.Time {
+	return time.Date(2024, 5, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestAdvance(t *testing.T) {
+	until := date(10, 0)
+	tests := []struct {
+		name       string
+		recurrence string
+		until      *time.Time
+		after      time.Time
+		now        time.Time
+		wantStart  *time.Time
+	}{
+		{name: "without recurrence", after: date(5, 4), now: date(5, 4)},
+		{name: "next occurrence", recurrence: "0 2 * * SUN", after: date(5, 4), now: date(5, 4),
+			wantStart: ptr(date(12, 2))},
+		{name: "occurrence starting when the previous ended", recurrence: "0 * * * *", after: date(5, 4),
+			now: date(5, 4), wantStart: ptr(date(5, 4))},
+		{name: "missed occurrences", recurrence: "0 2 * * SUN", after: date(5, 4), now: date(20, 3),
+			wantStart: ptr(date(26, 2))},
+		{name: "occurrence in progress", recurrence: "0 2 * * SUN", after: date(5, 4), now: date(19, 3),
+			wantStart: ptr(date(19, 2))},
+		{name: "past until", recurrence: "0 2 * * SUN", until: &until, after: date(5, 4), now: date(5, 4)},
+	}
+	for _, test := range tests {
+		// occurrences last two hours
+		window := &models.MaintenanceWindow{Start: date(5, 2), End: date(5, 4), Recurrence: test.recurrence,
+			Until: test.until, InProgress: true}
+		if err := advance(window, test.after, test.now); err != nil {
+			t.Errorf("%s: advance() error = %v", test.name, err)
+			continue
+		}
+		if window.InProgress {
+			t.Errorf("%s: InProgress = true, want false", test.name)
+		}
+		if test.wantStart == nil {
+			if window.NextStart != nil || window.NextEnd != nil {
+				t.Errorf("%s: next occurrence = %v - %v, want none", test.name, window.NextStart, window.NextEnd)
+			}
+			continue
+		}
+		wantEnd := test.wantStart.Add(2 * time.Hour)
+		if window.NextStart == nil || !window.NextStart.Equal(*test.wantStart) || !window.NextEnd.Equal(wantEnd) {
+			t.Errorf("%s: next occurrence = %v - %v, want %s - %s", test.name, window.NextStart, window.NextEnd,
+				test.wantStart, wantEnd)
+		}
+	}
+}
+
+func TestAdvanceRejectsInvalidRecurrence(t *testing.T) {
+	window := &models.MaintenanceWindow{Start: date(5, 2), End: date(5, 4), Recurrence: "RRULE:FREQ=WEEKLY"}
+	if err := advance(window, date(5, 4), date(5, 4)); err == nil {
+		t.Errorf("advance() error = nil, want the recurrence rejected")
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so that scanners
+// cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records the count and latency of every request, labelled with
+// the route pattern rather than the raw path.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
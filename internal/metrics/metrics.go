@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"time"
+)
+
+const namespace = "automation_hub"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of gorm operations by operation, table and result.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "result"})
+
+	eventPublishes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_publish_total",
+		Help:      "Event publishes by transport and result.",
+	}, []string{"transport", "result"})
+
+	eventPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_publish_duration_seconds",
+		Help:      "Event publish latency by transport.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"transport"})
+
+	imageUploadBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_upload_bytes",
+		Help:      "Size of accepted image uploads.",
+		Buckets:   prometheus.ExponentialBuckets(16*1024, 4, 8),
+	})
+
+	imageRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_rejections_total",
+		Help:      "Rejected image uploads by reason.",
+	}, []string{"reason"})
+)
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ObservePublish records one call to an event publisher.
+func ObservePublish(transport string, duration time.Duration, err error) {
+	eventPublishes.WithLabelValues(transport, result(err)).Inc()
+	eventPublishDuration.WithLabelValues(transport).Observe(duration.Seconds())
+}
+
+// ObserveImageUpload records the size of an accepted image.
+func ObserveImageUpload(bytes int64) {
+	imageUploadBytes.Observe(float64(bytes))
+}
+
+// RejectImage records an image refused for reason.
+func RejectImage(reason string) {
+	imageRejections.WithLabelValues(reason).Inc()
+}
+
+// HealthCounter returns the number of automations in every health state.
+type HealthCounter func() (map[string]int, error)
+
+type healthCollector struct {
+	count HealthCounter
+	desc  *prometheus.Desc
+}
+
+// RegisterHealthCounter exposes the automations per health state as a gauge
+// that is computed on every scrape.
+func RegisterHealthCounter(count HealthCounter) {
+	prometheus.MustRegister(&healthCollector{
+		count: count,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "automations"),
+			"Automations by health state.", []string{"state"}, nil),
+	})
+}
+
+func (c *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), state)
+	}
+}
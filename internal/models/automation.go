@@ -4,24 +4,47 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
+	"gorm.io/gorm"
 	"mime/multipart"
+	"strings"
+	"unicode"
 )
 
 var JSON = jsoniter.ConfigCompatibleWithStandardLibrary
 
 type Automation struct {
-	ID          uuid.UUID             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
-	Name        string                `gorm:"type:varchar(50);unique" json:"name,omitempty"`
-	URLPath     string                `gorm:"type:varchar(255);unique" json:"urlPath,omitempty"`
-	Image       string                `gorm:"type:varchar(255)" json:"image,omitempty"`
-	Host        string                `gorm:"type:varchar(50)" json:"host,omitempty"`
-	Port        int                   `gorm:"check:port >= 0 AND port <= 65535" json:"port,omitempty"`
-	Position    int                   `gorm:"type:int;unique;check:position >= 0" json:"position,omitempty,omitinput"`
-	ImageFile   *multipart.FileHeader `json:"imageFile,omitempty" gorm:"-"`
-	RemoveImage bool                  `json:"removeImage,omitempty" gorm:"-"`
-	OldUrlPath  string                `json:"oldUrlPath,omitempty" gorm:"-"`
+	ID            uuid.UUID                `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	WorkspaceID   uuid.UUID                `gorm:"type:uuid;index" json:"workspaceId,omitempty"`
+	Workspace     *Workspace               `gorm:"constraint:OnDelete:RESTRICT" json:"workspace,omitempty"`
+	Name          string                   `gorm:"type:varchar(50)" json:"name,omitempty"`
+	URLPath       string                   `gorm:"type:varchar(255)" json:"urlPath,omitempty"`
+	Image         string                   `gorm:"type:varchar(255)" json:"image,omitempty"`
+	Host          string                   `gorm:"type:varchar(50)" json:"host,omitempty"`
+	Port          int                      `gorm:"check:port >= 0 AND port <= 65535" json:"port,omitempty"`
+	Position      int                      `gorm:"type:int;check:position >= 0" json:"position,omitempty,omitinput"`
+	Routing       *RoutingOptions          `gorm:"type:jsonb;serializer:json" json:"routingOptions,omitempty"`
+	LoadBalancing string                   `gorm:"type:varchar(20)" json:"loadBalancing,omitempty"`
+	Targets       []*UpstreamTarget        `gorm:"foreignKey:AutomationID;constraint:OnDelete:CASCADE" json:"targets,omitempty"`
+	HealthCheck   *HealthCheck             `gorm:"foreignKey:AutomationID;constraint:OnDelete:CASCADE" json:"healthCheck,omitempty"`
+	Health        *HealthStatus            `gorm:"foreignKey:AutomationID;constraint:OnDelete:CASCADE" json:"health,omitempty"`
+	Environments  []*AutomationEnvironment `gorm:"foreignKey:AutomationID;constraint:OnDelete:CASCADE" json:"environments,omitempty"`
+	Redirects     []*URLPathRedirect       `gorm:"foreignKey:AutomationID;constraint:OnDelete:CASCADE" json:"redirects,omitempty"`
+	Tags          []*Tag                   `gorm:"many2many:automation_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	CategoryID    *uuid.UUID               `gorm:"type:uuid;index" json:"categoryId,omitempty"`
+	Category      *Category                `gorm:"constraint:OnDelete:SET NULL" json:"category,omitempty"`
+	Metadata      `gorm:"embedded"`
+	Lifecycle     `gorm:"embedded"`
+	ImageFile     *multipart.FileHeader `json:"imageFile,omitempty" gorm:"-"`
+	RemoveImage   bool                  `json:"removeImage,omitempty" gorm:"-"`
+	OldUrlPath    string                `json:"oldUrlPath,omitempty" gorm:"-"`
+	DeletedAt     gorm.DeletedAt        `gorm:"index" json:"deletedAt" swaggertype:"string"`
 }
 
+// AutomationUniqueColumns must be unique within a workspace among the
+// automations that are not in the trash, so a trashed automation does not
+// block its name or path.
+var AutomationUniqueColumns = []string{"name", "url_path", "position"}
+
 func (a *Automation) Validate() error {
 	if a.Name == "" {
 		return fmt.Errorf("name is required")
@@ -29,6 +52,9 @@ func (a *Automation) Validate() error {
 	if len(a.Name) > 50 {
 		return fmt.Errorf("name is too long, maximum length is 50 characters")
 	}
+	if strings.IndexFunc(a.Name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("name cannot contain line breaks or other control characters")
+	}
 	if a.URLPath == "" {
 		return fmt.Errorf("urlPath is required")
 	}
@@ -50,5 +76,35 @@ func (a *Automation) Validate() error {
 	if a.Position < 0 {
 		return fmt.Errorf("position cannot be negative")
 	}
+	if !ValidLoadBalancingPolicy(a.LoadBalancing) {
+		return fmt.Errorf("loadBalancing must be one of %s, %s, %s or %s", RoundRobinPolicy, LeastConnPolicy,
+			IPHashPolicy, PrimaryBackupPolicy)
+	}
+	if err := a.validateTargets(); err != nil {
+		return err
+	}
+	if a.Routing != nil {
+		if err := a.Routing.Validate(); err != nil {
+			return fmt.Errorf("routingOptions: %w", err)
+		}
+	}
+	if err := a.Metadata.Validate(); err != nil {
+		return err
+	}
+	if err := a.Lifecycle.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *Automation) validateTargets() error {
+	for _, target := range a.Targets {
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("target %s:%d: %w", target.Host, target.Port, err)
+		}
+		if target.Backup && a.LoadBalancing == IPHashPolicy {
+			return fmt.Errorf("backup targets cannot be used with the %s policy", IPHashPolicy)
+		}
+	}
 	return nil
 }
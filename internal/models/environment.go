@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"regexp"
+	"time"
+)
+
+// environmentNamePattern keeps environment names usable as the first segment
+// of a URL path.
+var environmentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,19}$`)
+
+// AutomationEnvironment is a further deployment of an automation, such as
+// "staging", served at /<name>/<urlPath>. Host, Port and Routing override
+// those of the automation where they are set; the targets belong to the main
+// deployment and are not used.
+type AutomationEnvironment struct {
+	ID           uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	AutomationID uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_automation_environments_name,priority:1" json:"automationId,omitempty"`
+	Name         string          `gorm:"type:varchar(20);uniqueIndex:idx_automation_environments_name,priority:2" json:"name,omitempty"`
+	Host         string          `gorm:"type:varchar(50)" json:"host,omitempty"`
+	Port         int             `gorm:"check:port >= 0 AND port <= 65535" json:"port,omitempty"`
+	Routing      *RoutingOptions `gorm:"type:jsonb;serializer:json" json:"routingOptions,omitempty"`
+	// URLPath is derived from the name and the path of the automation.
+	URLPath   string    `gorm:"-" json:"urlPath,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (e *AutomationEnvironment) Validate() error {
+	if !environmentNamePattern.MatchString(e.Name) {
+		return fmt.Errorf("name must be 1 to 20 lowercase letters, digits or dashes")
+	}
+	if len(e.Host) > 50 {
+		return fmt.Errorf("hostname is too long, maximum length is 50 characters")
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		return fmt.Errorf("error: Port %d is not valid", e.Port)
+	}
+	if e.Routing != nil {
+		if err := e.Routing.Validate(); err != nil {
+			return fmt.Errorf("routingOptions: %w", err)
+		}
+	}
+	return nil
+}
+
+// Promote copies the configuration of e onto target.
+func (e *AutomationEnvironment) Promote(target *AutomationEnvironment) {
+	target.Host = e.Host
+	target.Port = e.Port
+	target.Routing = e.Routing
+}
+
+// Environment returns the environment with the given name, or nil.
+func (a *Automation) Environment(name string) *AutomationEnvironment {
+	for _, environment := range a.Environments {
+		if environment.Name == name {
+			return environment
+		}
+	}
+	return nil
+}
+
+// SetEnvironmentPaths derives the URL paths of the environments from the
+// path of the automation. It must be called whenever the path changes.
+func (a *Automation) SetEnvironmentPaths() {
+	for _, environment := range a.Environments {
+		environment.URLPath = environment.Name + "/" + a.URLPath
+	}
+}
+
+// AfterFind derives the URL paths of the loaded environments.
+func (a *Automation) AfterFind(tx *gorm.DB) error {
+	a.SetEnvironmentPaths()
+	return nil
+}
+
+// ForEnvironment returns a copy of the automation with the host, port and
+// routing options of environment applied and without targets.
+func (a *Automation) ForEnvironment(environment *AutomationEnvironment) *Automation {
+	deployed := *a
+	deployed.Targets = nil
+	deployed.LoadBalancing = ""
+	if environment.Host != "" {
+		deployed.Host = environment.Host
+	}
+	if environment.Port != 0 {
+		deployed.Port = environment.Port
+	}
+	if environment.Routing != nil {
+		deployed.Routing = environment.Routing
+	}
+	return &deployed
+}
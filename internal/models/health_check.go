@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	TCPCheck  = "tcp"
+	HTTPCheck = "http"
+
+	HealthUnknown   = "unknown"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+
+	defaultCheckInterval      = 30
+	defaultCheckTimeout       = 5
+	defaultHealthyThreshold   = 1
+	defaultUnhealthyThreshold = 3
+	defaultExpectedStatus     = 200
+	minCheckInterval          = 5
+	maxCheckInterval          = 86400
+	maxCheckThreshold         = 100
+	maxCheckPathLength        = 255
+)
+
+// HealthCheck defines how the prober checks the upstream of an automation.
+// Automations without a definition get a TCP check with the default values.
+// Interval and Timeout are in seconds.
+type HealthCheck struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	AutomationID       uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"automationId,omitempty"`
+	Type               string    `gorm:"type:varchar(10)" json:"type,omitempty"`
+	Path               string    `gorm:"type:varchar(255)" json:"path,omitempty"`
+	ExpectedStatus     int       `gorm:"type:int" json:"expectedStatus,omitempty"`
+	Interval           int       `gorm:"type:int" json:"interval,omitempty"`
+	Timeout            int       `gorm:"type:int" json:"timeout,omitempty"`
+	HealthyThreshold   int       `gorm:"type:int" json:"healthyThreshold,omitempty"`
+	UnhealthyThreshold int       `gorm:"type:int" json:"unhealthyThreshold,omitempty"`
+}
+
+// DefaultHealthCheck is the check used for automations without a definition.
+func DefaultHealthCheck(automationID uuid.UUID) *HealthCheck {
+	check := &HealthCheck{AutomationID: automationID}
+	check.ApplyDefaults()
+	return check
+}
+
+// ApplyDefaults fills in the fields left empty.
+func (h *HealthCheck) ApplyDefaults() {
+	if h.Type == "" {
+		h.Type = TCPCheck
+	}
+	if h.Type == HTTPCheck {
+		if h.Path == "" {
+			h.Path = "/"
+		}
+		if h.ExpectedStatus == 0 {
+			h.ExpectedStatus = defaultExpectedStatus
+		}
+	}
+	if h.Interval == 0 {
+		h.Interval = defaultCheckInterval
+	}
+	if h.Timeout == 0 {
+		h.Timeout = defaultCheckTimeout
+	}
+	if h.HealthyThreshold == 0 {
+		h.HealthyThreshold = defaultHealthyThreshold
+	}
+	if h.UnhealthyThreshold == 0 {
+		h.UnhealthyThreshold = defaultUnhealthyThreshold
+	}
+}
+
+func (h *HealthCheck) Validate() error {
+	switch h.Type {
+	case TCPCheck:
+		if h.Path != "" {
+			return fmt.Errorf("path can only be set for %s checks", HTTPCheck)
+		}
+	case HTTPCheck:
+		if !strings.HasPrefix(h.Path, "/") || len(h.Path) > maxCheckPathLength {
+			return fmt.Errorf("path must start with / and be at most %d characters", maxCheckPathLength)
+		}
+		if h.ExpectedStatus < 100 || h.ExpectedStatus > 599 {
+			return fmt.Errorf("expectedStatus %d is not a valid HTTP status", h.ExpectedStatus)
+		}
+	default:
+		return fmt.Errorf("type must be %s or %s", TCPCheck, HTTPCheck)
+	}
+	if h.Interval < minCheckInterval || h.Interval > maxCheckInterval {
+		return fmt.Errorf("interval must be between %d and %d seconds", minCheckInterval, maxCheckInterval)
+	}
+	if h.Timeout < 1 || h.Timeout > h.Interval {
+		return fmt.Errorf("timeout must be between 1 second and the interval")
+	}
+	if h.HealthyThreshold < 1 || h.HealthyThreshold > maxCheckThreshold {
+		return fmt.Errorf("healthyThreshold must be between 1 and %d", maxCheckThreshold)
+	}
+	if h.UnhealthyThreshold < 1 || h.UnhealthyThreshold > maxCheckThreshold {
+		return fmt.Errorf("unhealthyThreshold must be between 1 and %d", maxCheckThreshold)
+	}
+	return nil
+}
+
+func (h *HealthCheck) IntervalDuration() time.Duration {
+	return time.Duration(h.Interval) * time.Second
+}
+
+func (h *HealthCheck) TimeoutDuration() time.Duration {
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// HealthStatus is the last known health of an automation's upstream.
+type HealthStatus struct {
+	AutomationID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"-"`
+	Status               string     `gorm:"type:varchar(20)" json:"status"`
+	ConsecutiveSuccesses int        `gorm:"type:int" json:"consecutiveSuccesses"`
+	ConsecutiveFailures  int        `gorm:"type:int" json:"consecutiveFailures"`
+	LatencyMs            int64      `json:"latencyMs"`
+	LastError            string     `gorm:"type:text" json:"lastError,omitempty"`
+	LastCheckedAt        *time.Time `json:"lastCheckedAt,omitempty"`
+	LastChangedAt        *time.Time `json:"lastChangedAt,omitempty"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lifecycle states.
+const (
+	StateActive      = "active"
+	StateDisabled    = "disabled"
+	StateMaintenance = "maintenance"
+)
+
+const maxStateMessageLength = 500
+
+// Lifecycle is whether an automation takes traffic. A disabled automation is
+// not exposed at all; one in maintenance is answered by the gateway with a
+// maintenance page showing the message and, when known, when it is expected
+// back.
+type Lifecycle struct {
+	State          string     `gorm:"type:varchar(20);not null;default:'active';index" json:"state,omitempty"`
+	StateMessage   string     `gorm:"type:varchar(500)" json:"stateMessage,omitempty"`
+	StateETA       *time.Time `json:"stateEta,omitempty"`
+	StateChangedAt *time.Time `json:"stateChangedAt,omitempty"`
+}
+
+func ValidState(state string) bool {
+	switch state {
+	case StateActive, StateDisabled, StateMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Lifecycle) Validate() error {
+	if !ValidState(l.State) {
+		return fmt.Errorf("state must be one of %s, %s or %s", StateActive, StateDisabled, StateMaintenance)
+	}
+	if len(l.StateMessage) > maxStateMessageLength {
+		return fmt.Errorf("state message is too long, maximum length is %d characters", maxStateMessageLength)
+	}
+	if l.State == StateActive && (l.StateMessage != "" || l.StateETA != nil) {
+		return fmt.Errorf("an active automation cannot have a state message or ETA")
+	}
+	return nil
+}
+
+// Active reports whether the automation takes traffic normally.
+func (l *Lifecycle) Active() bool {
+	return l.State == StateActive || l.State == ""
+}
+
+// InMaintenance reports whether the gateway answers with a maintenance page.
+func (l *Lifecycle) InMaintenance() bool {
+	return l.State == StateMaintenance
+}
+
+// Disabled reports whether the automation is left out of the gateway.
+func (l *Lifecycle) Disabled() bool {
+	return l.State == StateDisabled
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// MaintenanceWindow plans maintenance of an automation ahead of time. Start
+// and End bound the first occurrence; a window with a recurrence repeats at
+// every start of its cron expression, for as long as the first occurrence
+// lasted, until Until.
+type MaintenanceWindow struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	AutomationID uuid.UUID   `gorm:"type:uuid;index" json:"automationId"`
+	Automation   *Automation `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Start        time.Time   `gorm:"column:starts_at" json:"start"`
+	End          time.Time   `gorm:"column:ends_at" json:"end"`
+	// Recurrence is a cron expression in UTC, e.g. "0 2 * * SUN". RRULEs are
+	// not supported.
+	Recurrence string     `gorm:"type:varchar(100)" json:"recurrence,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	Message    string     `gorm:"type:varchar(500)" json:"message,omitempty"`
+	// NextStart and NextEnd bound the occurrence that is in progress or comes
+	// next. They are cleared once the window has no occurrences left.
+	NextStart  *time.Time `gorm:"index" json:"nextStart,omitempty"`
+	NextEnd    *time.Time `gorm:"index" json:"nextEnd,omitempty"`
+	InProgress bool       `json:"inProgress"`
+	CreatedBy  string     `gorm:"type:varchar(255)" json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (w *MaintenanceWindow) Validate() error {
+	if w.Start.IsZero() || w.End.IsZero() {
+		return fmt.Errorf("start and end are required")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if len(w.Recurrence) > 100 {
+		return fmt.Errorf("recurrence is too long, maximum length is 100 characters")
+	}
+	if w.Until != nil {
+		if w.Recurrence == "" {
+			return fmt.Errorf("until needs a recurrence")
+		}
+		if w.Until.Before(w.Start) {
+			return fmt.Errorf("until must not be before start")
+		}
+	}
+	if len(w.Message) > maxStateMessageLength {
+		return fmt.Errorf("message is too long, maximum length is %d characters", maxStateMessageLength)
+	}
+	return nil
+}
+
+// Duration is how long every occurrence of the window lasts.
+func (w *MaintenanceWindow) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Finished reports whether the window has no occurrences left.
+func (w *MaintenanceWindow) Finished() bool {
+	return w.NextStart == nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+)
+
+const (
+	maxDescriptionLength = 10000
+	maxLabels            = 64
+	maxLabelLength       = 63
+)
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]*[A-Za-z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+)
+
+// Metadata describes an automation for the people operating it. None of it
+// affects routing.
+type Metadata struct {
+	// Description is markdown.
+	Description      string            `gorm:"type:text" json:"description,omitempty"`
+	Owner            string            `gorm:"type:varchar(100)" json:"owner,omitempty"`
+	Team             string            `gorm:"type:varchar(100)" json:"team,omitempty"`
+	ContactEmail     string            `gorm:"type:varchar(255)" json:"contactEmail,omitempty"`
+	DocumentationURL string            `gorm:"type:varchar(2048)" json:"documentationUrl,omitempty"`
+	SourceRepoURL    string            `gorm:"type:varchar(2048)" json:"sourceRepoUrl,omitempty"`
+	Labels           map[string]string `gorm:"type:jsonb;serializer:json;index:idx_automations_labels,type:gin" json:"labels,omitempty"`
+}
+
+func (m *Metadata) Validate() error {
+	if len(m.Description) > maxDescriptionLength {
+		return fmt.Errorf("description is too long, maximum length is %d characters", maxDescriptionLength)
+	}
+	if len(m.Owner) > 100 {
+		return fmt.Errorf("owner is too long, maximum length is 100 characters")
+	}
+	if len(m.Team) > 100 {
+		return fmt.Errorf("team is too long, maximum length is 100 characters")
+	}
+	if m.ContactEmail != "" {
+		if len(m.ContactEmail) > 255 {
+			return fmt.Errorf("contactEmail is too long, maximum length is 255 characters")
+		}
+		address, err := mail.ParseAddress(m.ContactEmail)
+		if err != nil || address.Address != m.ContactEmail {
+			return fmt.Errorf("contactEmail must be a plain email address")
+		}
+	}
+	if err := validateLink("documentationUrl", m.DocumentationURL); err != nil {
+		return err
+	}
+	if err := validateLink("sourceRepoUrl", m.SourceRepoURL); err != nil {
+		return err
+	}
+	return ValidateLabels(m.Labels)
+}
+
+// ValidateLabels checks the number of labels and the syntax of their keys and
+// values, which keeps them usable in label selectors.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("labels has too many entries, maximum is %d", maxLabels)
+	}
+	for key, value := range labels {
+		if len(key) > maxLabelLength || !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("label key %q must be 1 to %d letters, digits, dashes, underscores, dots or slashes",
+				key, maxLabelLength)
+		}
+		if len(value) > maxLabelLength || !labelValuePattern.MatchString(value) {
+			return fmt.Errorf("label %q has an invalid value %q", key, value)
+		}
+	}
+	return nil
+}
+
+func validateLink(field string, link string) error {
+	if link == "" {
+		return nil
+	}
+	if len(link) > 2048 {
+		return fmt.Errorf("%s is too long, maximum length is 2048 characters", field)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL", field)
+	}
+	return nil
+}
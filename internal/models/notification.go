@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+const (
+	WebhookChannel = "webhook"
+	SlackChannel   = "slack"
+	SMTPChannel    = "smtp"
+
+	NotifyDown    = "down"
+	NotifyUp      = "up"
+	NotifyCreated = "created"
+	NotifyUpdated = "updated"
+	NotifyDeleted = "deleted"
+
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationChannel is a destination for notifications. Webhook and Slack
+// channels post to URL; Slack channels use the incoming-webhook payload that
+// Mattermost accepts as well. Secret and SMTPPassword are never returned by
+// the API; an update that leaves them empty keeps them, unless ClearSecret or
+// ClearSMTPPassword asks to remove them.
+type NotificationChannel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	Name         string    `gorm:"type:varchar(50);unique" json:"name,omitempty"`
+	Type         string    `gorm:"type:varchar(20)" json:"type,omitempty"`
+	URL          string    `gorm:"type:varchar(2048)" json:"url,omitempty"`
+	Secret       string    `gorm:"type:varchar(255)" json:"secret,omitempty"`
+	SMTPHost     string    `gorm:"type:varchar(255)" json:"smtpHost,omitempty"`
+	SMTPPort     int       `gorm:"type:int" json:"smtpPort,omitempty"`
+	SMTPUsername string    `gorm:"type:varchar(255)" json:"smtpUsername,omitempty"`
+	SMTPPassword string    `gorm:"type:varchar(255)" json:"smtpPassword,omitempty"`
+	From         string    `gorm:"type:varchar(255)" json:"from,omitempty"`
+	To           []string  `gorm:"type:jsonb;serializer:json" json:"to,omitempty"`
+	Enabled      bool      `json:"enabled"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// ClearSecret and ClearSMTPPassword remove the credential on update.
+	ClearSecret       bool `json:"clearSecret,omitempty" gorm:"-"`
+	ClearSMTPPassword bool `json:"clearSmtpPassword,omitempty" gorm:"-"`
+}
+
+func (c *NotificationChannel) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(c.Name) > 50 {
+		return fmt.Errorf("name is too long, maximum length is 50 characters")
+	}
+
+	switch c.Type {
+	case WebhookChannel, SlackChannel:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+	case SMTPChannel:
+		if c.SMTPHost == "" {
+			return fmt.Errorf("smtpHost is required")
+		}
+		if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+			return fmt.Errorf("error: Port %d is not valid", c.SMTPPort)
+		}
+		if _, err := mail.ParseAddress(c.From); err != nil {
+			return fmt.Errorf("from is not a valid address: %w", err)
+		}
+		if len(c.To) == 0 {
+			return fmt.Errorf("to needs at least one recipient")
+		}
+		for _, to := range c.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("to %q is not a valid address: %w", to, err)
+			}
+		}
+	default:
+		return fmt.Errorf("type must be one of %s, %s or %s", WebhookChannel, SlackChannel, SMTPChannel)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the channel without its credentials.
+func (c *NotificationChannel) Redacted() *NotificationChannel {
+	redacted := *c
+	redacted.Secret = ""
+	redacted.SMTPPassword = ""
+	return &redacted
+}
+
+// NotificationRule sends the listed events to a channel. A rule without
+// automation IDs applies to every automation, and a rule without tags to
+// automations with any tags; a rule with tags wants automations carrying at
+// least one of them.
+type NotificationRule struct {
+	ID            uuid.UUID            `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	ChannelID     uuid.UUID            `gorm:"type:uuid;index;not null" json:"channelId,omitempty"`
+	Channel       *NotificationChannel `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Name          string               `gorm:"type:varchar(50)" json:"name,omitempty"`
+	Events        []string             `gorm:"type:jsonb;serializer:json" json:"events,omitempty"`
+	AutomationIDs []uuid.UUID          `gorm:"type:jsonb;serializer:json" json:"automationIds,omitempty"`
+	Tags          []string             `gorm:"type:jsonb;serializer:json" json:"tags,omitempty"`
+	Enabled       bool                 `json:"enabled"`
+}
+
+func (r *NotificationRule) Validate() error {
+	if r.ChannelID == uuid.Nil {
+		return fmt.Errorf("channelId is required")
+	}
+	if len(r.Name) > 50 {
+		return fmt.Errorf("name is too long, maximum length is 50 characters")
+	}
+	if len(r.Events) == 0 {
+		return fmt.Errorf("events needs at least one event")
+	}
+	for _, event := range r.Events {
+		switch event {
+		case NotifyDown, NotifyUp, NotifyCreated, NotifyUpdated, NotifyDeleted:
+		default:
+			return fmt.Errorf("event %q must be one of %s, %s, %s, %s or %s", event, NotifyDown, NotifyUp,
+				NotifyCreated, NotifyUpdated, NotifyDeleted)
+		}
+	}
+	for _, name := range r.Tags {
+		tag := Tag{Name: name}
+		if err := tag.Validate(); err != nil {
+			return fmt.Errorf("tag %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule wants event for the automation.
+func (r *NotificationRule) Matches(event string, automation *Automation) bool {
+	if !r.Enabled {
+		return false
+	}
+	wanted := false
+	for _, e := range r.Events {
+		wanted = wanted || e == event
+	}
+	if !wanted {
+		return false
+	}
+	if len(r.Tags) > 0 && !automation.HasAnyTag(r.Tags) {
+		return false
+	}
+	if len(r.AutomationIDs) == 0 {
+		return true
+	}
+	for _, id := range r.AutomationIDs {
+		if id == automation.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationDelivery is one notification on its way to one channel. The
+// rows double as the delivery log. A notification is queued for a channel at
+// most once, even when the event behind it is redelivered.
+type NotificationDelivery struct {
+	ID             uint64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	NotificationID uuid.UUID   `gorm:"type:uuid;uniqueIndex:idx_notification_deliveries_once,priority:1" json:"notificationId"`
+	ChannelID      uuid.UUID   `gorm:"type:uuid;index;uniqueIndex:idx_notification_deliveries_once,priority:2" json:"channelId"`
+	RuleID         uuid.UUID   `gorm:"type:uuid" json:"ruleId"`
+	AutomationID   uuid.UUID   `gorm:"type:uuid;index" json:"automationId"`
+	Automation     *Automation `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Event          string      `gorm:"type:varchar(20)" json:"event"`
+	Payload        string      `gorm:"type:jsonb" json:"payload"`
+	Status         string      `gorm:"type:varchar(20);index" json:"status"`
+	Attempts       int         `gorm:"type:int;default:0" json:"attempts"`
+	LastError      string      `gorm:"type:text" json:"lastError,omitempty"`
+	CreatedAt      time.Time   `gorm:"index" json:"createdAt"`
+	NextAttemptAt  time.Time   `gorm:"index" json:"nextAttemptAt"`
+	DeliveredAt    *time.Time  `json:"deliveredAt,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type OutboxMessage struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	AggregateID   string     `gorm:"type:varchar(64);index" json:"aggregateId"`
+	EventType     string     `gorm:"type:varchar(50)" json:"eventType"`
+	Payload       string     `gorm:"type:jsonb" json:"payload"`
+	Attempts      int        `gorm:"type:int;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	NextAttemptAt time.Time  `gorm:"index" json:"nextAttemptAt"`
+	PublishedAt   *time.Time `gorm:"index" json:"publishedAt,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// URLPathRedirect is a URL path an automation had before it was renamed. The
+// path stays reserved for the automation within its workspace, so that
+// bookmarks keep leading to it, until the automation is purged or takes the
+// path back.
+type URLPathRedirect struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"-"`
+	WorkspaceID  uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_url_path_redirects_path,priority:1" json:"-"`
+	URLPath      string    `gorm:"type:varchar(255);uniqueIndex:idx_url_path_redirects_path,priority:2" json:"urlPath"`
+	AutomationID uuid.UUID `gorm:"type:uuid;index" json:"-"`
+	// CreatedAt is when the automation moved away from the path.
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Revision actions.
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionRollback = "rollback"
+	RevisionState    = "state"
+)
+
+// AutomationRevision records one change of an automation: who made it, the
+// state it left the automation in and the fields it changed. Revisions are
+// numbered from 1 per automation.
+type AutomationRevision struct {
+	ID           uint64              `gorm:"primaryKey;autoIncrement" json:"-"`
+	AutomationID uuid.UUID           `gorm:"type:uuid;uniqueIndex:idx_automation_revisions_number,priority:1" json:"automationId"`
+	Revision     int                 `gorm:"uniqueIndex:idx_automation_revisions_number,priority:2" json:"revision"`
+	Action       string              `gorm:"type:varchar(20)" json:"action"`
+	Actor        string              `gorm:"type:varchar(255)" json:"actor,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	Snapshot     *AutomationSnapshot `gorm:"type:jsonb;serializer:json" json:"snapshot"`
+	Changes      []FieldChange       `gorm:"type:jsonb;serializer:json" json:"changes"`
+	// RolledBackTo is the revision whose snapshot a rollback restored.
+	RolledBackTo int `json:"rolledBackTo,omitempty"`
+}
+
+// AutomationSnapshot holds the fields of an automation that revisions track.
+// Positions and images are left out: positions belong to the ordering rather
+// than the automation, and replaced images are deleted from disk. Of the
+// lifecycle, the time of the last state change is left out as well, since
+// the revision has its own.
+type AutomationSnapshot struct {
+	Name          string          `json:"name"`
+	URLPath       string          `json:"urlPath"`
+	Host          string          `json:"host"`
+	Port          int             `json:"port"`
+	Routing       *RoutingOptions `json:"routingOptions,omitempty"`
+	LoadBalancing string          `json:"loadBalancing,omitempty"`
+	CategoryID    *uuid.UUID      `json:"categoryId,omitempty"`
+	State         string          `json:"state,omitempty"`
+	StateMessage  string          `json:"stateMessage,omitempty"`
+	StateETA      *time.Time      `json:"stateEta,omitempty"`
+	Metadata
+}
+
+// FieldChange is the old and new value of one snapshot field, named after
+// its JSON key. Old is nil when the automation was created.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+func NewAutomationSnapshot(automation *Automation) *AutomationSnapshot {
+	return &AutomationSnapshot{
+		Name:          automation.Name,
+		URLPath:       automation.URLPath,
+		Host:          automation.Host,
+		Port:          automation.Port,
+		Routing:       automation.Routing,
+		LoadBalancing: automation.LoadBalancing,
+		CategoryID:    automation.CategoryID,
+		State:         automation.State,
+		StateMessage:  automation.StateMessage,
+		StateETA:      automation.StateETA,
+		Metadata:      automation.Metadata,
+	}
+}
+
+// Diff lists the fields that differ between previous and s. A nil previous
+// reports every field that is set.
+func (s *AutomationSnapshot) Diff(previous *AutomationSnapshot) []FieldChange {
+	changes := make([]FieldChange, 0)
+	var old map[string]reflect.Value
+	if previous != nil {
+		old = snapshotFields(reflect.ValueOf(previous).Elem())
+	}
+	current := reflect.ValueOf(s).Elem()
+	fields := snapshotFields(current)
+	for _, field := range snapshotFieldNames(current.Type()) {
+		newValue := fields[field]
+
+		var oldValue interface{}
+		if previous == nil {
+			if newValue.IsZero() {
+				continue
+			}
+		} else {
+			oldValue = old[field].Interface()
+			if reflect.DeepEqual(oldValue, newValue.Interface()) {
+				continue
+			}
+		}
+		changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue.Interface()})
+	}
+	return changes
+}
+
+// snapshotFields maps the JSON names of the snapshot fields, including those
+// of embedded structs, to their values.
+func snapshotFields(value reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous {
+			for name, nested := range snapshotFields(value.Field(i)) {
+				fields[name] = nested
+			}
+			continue
+		}
+		fields[jsonName(field)] = value.Field(i)
+	}
+	return fields
+}
+
+// snapshotFieldNames lists the JSON names of the snapshot fields in
+// declaration order, so that diffs are stable.
+func snapshotFieldNames(snapshotType reflect.Type) []string {
+	var names []string
+	for i := 0; i < snapshotType.NumField(); i++ {
+		field := snapshotType.Field(i)
+		if field.Anonymous {
+			names = append(names, snapshotFieldNames(field.Type)...)
+			continue
+		}
+		names = append(names, jsonName(field))
+	}
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// Apply copies the snapshot onto automation. The URL path is left alone
+// because it is derived from the name, and the state because it is changed
+// through SetState only.
+func (s *AutomationSnapshot) Apply(automation *Automation) {
+	automation.Name = s.Name
+	automation.Host = s.Host
+	automation.Port = s.Port
+	automation.Routing = s.Routing
+	automation.LoadBalancing = s.LoadBalancing
+	automation.CategoryID = s.CategoryID
+	automation.Metadata = s.Metadata
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	StripPrefixMode  = "strip"
+	PreservePathMode = "preserve"
+
+	maxTimeoutSeconds = 3600
+	maxBodySizeInMb   = 10240
+	maxHeaders        = 50
+	maxHeaderLength   = 255
+)
+
+// headerNamePattern accepts the letters, digits and dashes real header names
+// are made of. Names are written unquoted into proxy configs, so nothing
+// that a config syntax could interpret may pass.
+var headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// RoutingOptions tunes how the gateway proxies an automation. The zero value
+// proxies like before: plain HTTP, proxy default timeouts and the URL path
+// prefix stripped before the request reaches the upstream.
+type RoutingOptions struct {
+	Websocket       bool              `json:"websocket,omitempty"`
+	ConnectTimeout  int               `json:"connectTimeout,omitempty"`
+	ReadTimeout     int               `json:"readTimeout,omitempty"`
+	MaxBodySize     int               `json:"maxBodySize,omitempty"`
+	PathMode        string            `json:"pathMode,omitempty"`
+	RequestHeaders  map[string]string `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+}
+
+// PreservePath reports whether the upstream receives the full path, URL path
+// prefix included.
+func (o *RoutingOptions) PreservePath() bool {
+	return o != nil && o.PathMode == PreservePathMode
+}
+
+func (o *RoutingOptions) Validate() error {
+	if o.ConnectTimeout < 0 || o.ConnectTimeout > maxTimeoutSeconds {
+		return fmt.Errorf("connectTimeout must be between 0 and %d seconds", maxTimeoutSeconds)
+	}
+	if o.ReadTimeout < 0 || o.ReadTimeout > maxTimeoutSeconds {
+		return fmt.Errorf("readTimeout must be between 0 and %d seconds", maxTimeoutSeconds)
+	}
+	if o.MaxBodySize < 0 || o.MaxBodySize > maxBodySizeInMb {
+		return fmt.Errorf("maxBodySize must be between 0 and %d Mb", maxBodySizeInMb)
+	}
+	if o.PathMode != "" && o.PathMode != StripPrefixMode && o.PathMode != PreservePathMode {
+		return fmt.Errorf("pathMode must be %q or %q", StripPrefixMode, PreservePathMode)
+	}
+	if err := validateHeaders("requestHeaders", o.RequestHeaders); err != nil {
+		return err
+	}
+	return validateHeaders("responseHeaders", o.ResponseHeaders)
+}
+
+func validateHeaders(field string, headers map[string]string) error {
+	if len(headers) > maxHeaders {
+		return fmt.Errorf("%s cannot have more than %d headers", field, maxHeaders)
+	}
+	for name, value := range headers {
+		if len(name) > maxHeaderLength || !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("%s: %q is not a valid header name", field, name)
+		}
+		if len(value) > maxHeaderLength {
+			return fmt.Errorf("%s: value of %s is too long, maximum length is %d characters", field, name, maxHeaderLength)
+		}
+		// Values are written into proxy configs inside double quotes.
+		if strings.ContainsAny(value, "\"\\") || strings.IndexFunc(value, isControl) >= 0 {
+			return fmt.Errorf("%s: value of %s contains a forbidden character", field, name)
+		}
+	}
+	return nil
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
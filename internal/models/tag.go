@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+)
+
+// tagNamePattern keeps tag names usable as-is in URLs, gateway policies and
+// notification rules.
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,49}$`)
+
+// Tag labels automations; an automation can carry any number of tags.
+type Tag struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	Name string    `gorm:"type:varchar(50);uniqueIndex" json:"name,omitempty"`
+}
+
+func (t *Tag) Validate() error {
+	if !tagNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("name must be 1 to 50 lowercase letters, digits, dots, dashes or underscores")
+	}
+	return nil
+}
+
+// Category groups automations; an automation belongs to at most one.
+type Category struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	Name        string    `gorm:"type:varchar(50);uniqueIndex" json:"name,omitempty"`
+	Description string    `gorm:"type:varchar(255)" json:"description,omitempty"`
+}
+
+func (c *Category) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(c.Name) > 50 {
+		return fmt.Errorf("name is too long, maximum length is 50 characters")
+	}
+	if len(c.Description) > 255 {
+		return fmt.Errorf("description is too long, maximum length is 255 characters")
+	}
+	return nil
+}
+
+// TagNames returns the names of the tags of the automation.
+func (a *Automation) TagNames() []string {
+	names := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// HasAnyTag reports whether the automation carries at least one of names.
+func (a *Automation) HasAnyTag(names []string) bool {
+	for _, tag := range a.Tags {
+		for _, name := range names {
+			if tag.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
+
+const (
+	RoundRobinPolicy    = "round_robin"
+	LeastConnPolicy     = "least_conn"
+	IPHashPolicy        = "ip_hash"
+	PrimaryBackupPolicy = "primary_backup"
+
+	maxTargetWeight = 100
+)
+
+// UpstreamTarget is an additional upstream of an automation. The Host and
+// Port of the automation itself always remain its first, primary target.
+type UpstreamTarget struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	AutomationID uuid.UUID `gorm:"type:uuid;index;not null" json:"automationId,omitempty"`
+	Host         string    `gorm:"type:varchar(50)" json:"host,omitempty"`
+	Port         int       `gorm:"check:port >= 0 AND port <= 65535" json:"port,omitempty"`
+	Weight       int       `gorm:"type:int;default:1;check:weight >= 0" json:"weight,omitempty"`
+	Backup       bool      `json:"backup,omitempty"`
+}
+
+func (t *UpstreamTarget) Validate() error {
+	if t.Host == "" {
+		return fmt.Errorf("hostname is required")
+	}
+	if len(t.Host) > 50 {
+		return fmt.Errorf("hostname is too long, maximum length is 50 characters")
+	}
+	if t.Port <= 0 || t.Port > 65535 {
+		return fmt.Errorf("error: Port %d is not valid", t.Port)
+	}
+	if t.Weight < 0 || t.Weight > maxTargetWeight {
+		return fmt.Errorf("weight must be between 0 (default) and %d", maxTargetWeight)
+	}
+	return nil
+}
+
+// EffectiveWeight is the weight of the target, defaulting to 1.
+func (t *UpstreamTarget) EffectiveWeight() int {
+	if t.Weight == 0 {
+		return 1
+	}
+	return t.Weight
+}
+
+func ValidLoadBalancingPolicy(policy string) bool {
+	switch policy {
+	case "", RoundRobinPolicy, LeastConnPolicy, IPHashPolicy, PrimaryBackupPolicy:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UptimeSample is the outcome of a single probe. Samples are kept for a short
+// time only; older history lives on in hourly UptimeRollup rows.
+type UptimeSample struct {
+	ID           uint64      `gorm:"primaryKey;autoIncrement" json:"-"`
+	AutomationID uuid.UUID   `gorm:"type:uuid;index:idx_uptime_samples_automation_time,priority:1" json:"automationId"`
+	Automation   *Automation `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CheckedAt    time.Time   `gorm:"index:idx_uptime_samples_automation_time,priority:2;index" json:"checkedAt"`
+	Up           bool        `json:"up"`
+	LatencyMs    int64       `json:"latencyMs"`
+}
+
+// UptimeRollup summarises the samples of one automation over one hour. The
+// latency percentiles only cover successful probes.
+type UptimeRollup struct {
+	AutomationID uuid.UUID   `gorm:"type:uuid;primary_key" json:"automationId"`
+	Automation   *Automation `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	BucketStart  time.Time   `gorm:"primary_key" json:"bucketStart"`
+	Checks       int         `gorm:"type:int" json:"checks"`
+	Failures     int         `gorm:"type:int" json:"failures"`
+	LatencyP50Ms float64     `json:"latencyP50Ms"`
+	LatencyP95Ms float64     `json:"latencyP95Ms"`
+}
+
+// Outage is a period during which an automation was reported unhealthy. An
+// outage that is still going on has no EndedAt.
+type Outage struct {
+	ID           uint64      `gorm:"primaryKey;autoIncrement" json:"-"`
+	AutomationID uuid.UUID   `gorm:"type:uuid;index" json:"-"`
+	Automation   *Automation `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	StartedAt    time.Time   `gorm:"index" json:"startedAt"`
+	EndedAt      *time.Time  `gorm:"index" json:"endedAt,omitempty"`
+	Cause        string      `gorm:"type:text" json:"cause,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"time"
+)
+
+// DefaultWorkspaceSlug names the workspace that holds the automations created
+// before workspaces existed and that the routes outside /workspaces serve.
+const DefaultWorkspaceSlug = "default"
+
+// workspaceSlugPattern keeps slugs usable in URLs, gateway paths and Kafka
+// topic names.
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// Workspace isolates a set of automations: names, URL paths and positions are
+// unique within a workspace only. The slug identifies the workspace in the API
+// and in events and cannot be changed.
+type Workspace struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id,omitempty"`
+	Slug      string    `gorm:"type:varchar(50);uniqueIndex" json:"slug,omitempty"`
+	Name      string    `gorm:"type:varchar(100)" json:"name,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (w *Workspace) Validate() error {
+	if !workspaceSlugPattern.MatchString(w.Slug) {
+		return fmt.Errorf("slug must be 1 to 50 lowercase letters, digits or dashes")
+	}
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(w.Name) > 100 {
+		return fmt.Errorf("name is too long, maximum length is 100 characters")
+	}
+	return nil
+}
+
+func (w *Workspace) IsDefault() bool {
+	return w.Slug == DefaultWorkspaceSlug
+}
+
+// WorkspaceSlug returns the slug of the workspace of the automation, or an
+// empty string when it was not loaded.
+func (a *Automation) WorkspaceSlug() string {
+	if a.Workspace == nil {
+		return ""
+	}
+	return a.Workspace.Slug
+}
@@ -0,0 +1,148 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+const (
+	dispatchBatchSize = 50
+	minRetryDelay     = 5 * time.Second
+	maxRetryDelay     = time.Hour
+)
+
+// Dispatcher sends the queued deliveries. A failed delivery is retried with
+// exponential backoff until it has been attempted maxAttempts times, after
+// which it stays in the log as failed.
+type Dispatcher struct {
+	repo         Repository
+	senders      Senders
+	pollInterval time.Duration
+	maxAttempts  int
+	retention    time.Duration
+}
+
+func NewDispatcher(repo Repository, senders Senders, pollInterval time.Duration, maxAttempts int,
+	retention time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repo:         repo,
+		senders:      senders,
+		pollInterval: pollInterval,
+		maxAttempts:  maxAttempts,
+		retention:    retention,
+	}
+}
+
+func DefaultDispatcher() *Dispatcher {
+	return NewDispatcher(DefaultRepository(), NewSenders(config.AppConfig.NotifyTimeout),
+		config.AppConfig.NotifyPollInterval, config.AppConfig.NotifyMaxAttempts, config.AppConfig.NotifyRetention)
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		if _, err := d.repo.WithDispatchLock(d.dispatch); err != nil {
+			log.Printf("Failed to dispatch notifications: %v", err)
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			d.cleanup()
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch sends the due deliveries. Each outcome is written on its own as
+// soon as it is known, so a failure later in the batch does not send the
+// earlier deliveries again.
+func (d *Dispatcher) dispatch() error {
+	now := time.Now().UTC()
+	deliveries, err := d.repo.FindDueDeliveries(now, dispatchBatchSize)
+	if err != nil {
+		return err
+	}
+
+	channels := make(map[uuid.UUID]*models.NotificationChannel)
+	for _, delivery := range deliveries {
+		channel, ok := channels[delivery.ChannelID]
+		if !ok {
+			channel, err = d.repo.FindChannel(delivery.ChannelID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			channels[delivery.ChannelID] = channel
+		}
+
+		attempts := delivery.Attempts + 1
+		if errSend := d.send(channel, delivery); errSend != nil {
+			status := models.DeliveryPending
+			if attempts >= d.maxAttempts || errors.Is(errSend, errUndeliverable) {
+				status = models.DeliveryFailed
+			}
+			log.Printf("Failed to deliver notification %d (attempt %d): %v", delivery.ID, attempts, errSend)
+			if err := d.repo.MarkFailed(delivery.ID, attempts, status, now.Add(backoff(attempts)), errSend); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.repo.MarkDelivered(delivery.ID, attempts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errUndeliverable = errors.New("channel was removed or disabled")
+
+func (d *Dispatcher) send(channel *models.NotificationChannel, delivery *models.NotificationDelivery) error {
+	if channel == nil || !channel.Enabled {
+		return errUndeliverable
+	}
+
+	var notification Notification
+	if err := models.JSON.UnmarshalFromString(delivery.Payload, &notification); err != nil {
+		return err
+	}
+	return d.senders.Send(channel, &notification, []byte(delivery.Payload))
+}
+
+func backoff(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+func (d *Dispatcher) cleanup() {
+	if d.retention <= 0 {
+		return
+	}
+	deleted, err := d.repo.DeleteDeliveriesBefore(time.Now().UTC().Add(-d.retention))
+	if err != nil {
+		log.Printf("Failed to clean up notification log: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Removed %d notification deliveries", deleted)
+	}
+}
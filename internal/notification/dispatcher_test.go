@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// logRepository keeps the delivery log in memory. Only the methods the
+// dispatcher uses are implemented.
+type logRepository struct {
+	Repository
+	channels   map[uuid.UUID]*models.NotificationChannel
+	deliveries []*models.NotificationDelivery
+}
+
+func (r *logRepository) FindDueDeliveries(now time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	var due []*models.NotificationDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) && len(due) < limit {
+			copied := *delivery
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *logRepository) FindChannel(id uuid.UUID) (*models.NotificationChannel, error) {
+	channel, ok := r.channels[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return channel, nil
+}
+
+func (r *logRepository) MarkDelivered(id uint64, attempts int) error {
+	delivery := r.find(id)
+	now := time.Now().UTC()
+	delivery.Status, delivery.Attempts, delivery.DeliveredAt, delivery.LastError =
+		models.DeliveryDelivered, attempts, &now, ""
+	return nil
+}
+
+func (r *logRepository) MarkFailed(id uint64, attempts int, status string, nextAttemptAt time.Time,
+	cause error) error {
+	delivery := r.find(id)
+	delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError =
+		status, attempts, nextAttemptAt, cause.Error()
+	return nil
+}
+
+func (r *logRepository) find(id uint64) *models.NotificationDelivery {
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id {
+			return delivery
+		}
+	}
+	panic("unknown delivery")
+}
+
+func newLogRepository(channel *models.NotificationChannel) *logRepository {
+	payload, _ := models.JSON.MarshalToString(testNotification())
+	return &logRepository{
+		channels: map[uuid.UUID]*models.NotificationChannel{channel.ID: channel},
+		deliveries: []*models.NotificationDelivery{{
+			ID:            1,
+			ChannelID:     channel.ID,
+			Event:         models.NotifyDown,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now().UTC().Add(-time.Second),
+		}},
+	}
+}
+
+func TestDispatcherDeliversAndLogs(t *testing.T) {
+	server, requests := stubEndpoint(t, http.StatusOK)
+	channel := &models.NotificationChannel{ID: uuid.New(), Type: models.WebhookChannel, URL: server.URL,
+		Enabled: true}
+	repo := newLogRepository(channel)
+
+	dispatcher := NewDispatcher(repo, NewSenders(5*time.Second), time.Second, 3, 0)
+	if err := dispatcher.dispatch(); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+
+	<-requests
+	delivery := repo.deliveries[0]
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered on the first attempt", delivery)
+	}
+}
+
+func TestDispatcherRetriesWithBackoffUntilMaxAttempts(t *testing.T) {
+	server, _ := stubEndpoint(t, http.StatusInternalServerError)
+	channel := &models.NotificationChannel{ID: uuid.New(), Type: models.WebhookChannel, URL: server.URL,
+		Enabled: true}
+	repo := newLogRepository(channel)
+	dispatcher := NewDispatcher(repo, NewSenders(5*time.Second), time.Second, 3, 0)
+	delivery := repo.deliveries[0]
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now().UTC()
+		if err := dispatcher.dispatch(); err != nil {
+			t.Fatalf("dispatch() error = %v", err)
+		}
+		if delivery.Attempts != attempt || delivery.LastError == "" {
+			t.Fatalf("after attempt %d delivery = %+v, want the attempt and its error logged", attempt, delivery)
+		}
+		if attempt < 3 {
+			if delivery.Status != models.DeliveryPending {
+				t.Fatalf("after attempt %d status = %s, want %s", attempt, delivery.Status, models.DeliveryPending)
+			}
+			if wait := delivery.NextAttemptAt.Sub(before); wait < backoff(attempt) {
+				t.Errorf("after attempt %d next attempt in %s, want at least %s", attempt, wait, backoff(attempt))
+			}
+			// make the retry due
+			delivery.NextAttemptAt = time.Now().UTC().Add(-time.Second)
+		}
+	}
+	if delivery.Status != models.DeliveryFailed {
+		t.Errorf("status = %s after the last attempt, want %s", delivery.Status, models.DeliveryFailed)
+	}
+}
+
+func TestDispatcherFailsDeliveriesOfDisabledChannels(t *testing.T) {
+	channel := &models.NotificationChannel{ID: uuid.New(), Type: models.WebhookChannel, URL: "http://127.0.0.1:1"}
+	repo := newLogRepository(channel)
+
+	dispatcher := NewDispatcher(repo, NewSenders(time.Second), time.Second, 3, 0)
+	if err := dispatcher.dispatch(); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if delivery := repo.deliveries[0]; delivery.Status != models.DeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("delivery = %+v, want failed without retries", delivery)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 5 * time.Second},
+		{attempts: 2, want: 10 * time.Second},
+		{attempts: 5, want: 80 * time.Second},
+		{attempts: 10, want: 2560 * time.Second},
+		{attempts: 11, want: time.Hour},
+		{attempts: 50, want: time.Hour},
+	}
+	for _, test := range tests {
+		if got := backoff(test.attempts); got != test.want {
+			t.Errorf("backoff(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// GetChannels
+// @Summary Get all notification channels
+// @Description Retrieve all notification channels without their credentials
+// @Tags Notifications
+// @Produce  json
+// @Success 200 {array} models.NotificationChannel "Successfully retrieved channels"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/channels [get]
+func (h *Handler) GetChannels(c *gin.Context) {
+	channels, err := h.service.FindChannels(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// GetChannel
+// @Summary Get a notification channel by ID
+// @Description Retrieve a notification channel without its credentials
+// @Tags Notifications
+// @Produce  json
+// @Param id path string true "Channel ID"
+// @Success 200 {object} models.NotificationChannel "Successfully retrieved channel"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/channels/{id} [get]
+func (h *Handler) GetChannel(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	channel, err := h.service.FindChannel(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// CreateChannel
+// @Summary Create a notification channel
+// @Description Create a webhook, slack or smtp channel. Channels are enabled unless stated otherwise
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Param channel body models.NotificationChannel true "Channel data"
+// @Success 201 {object} models.NotificationChannel "Successfully created channel"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/channels [post]
+func (h *Handler) CreateChannel(c *gin.Context) {
+	channel := models.NotificationChannel{Enabled: true}
+	if !readBody(c, &channel) {
+		return
+	}
+
+	created, err := h.service.CreateChannel(c.Request.Context(), &channel)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateChannel
+// @Summary Update a notification channel
+// @Description Replace a notification channel. Empty credentials keep their current value unless clearSecret or clearSmtpPassword is set
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Channel ID"
+// @Param channel body models.NotificationChannel true "Channel data"
+// @Success 200 {object} models.NotificationChannel "Successfully updated channel"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/channels/{id} [patch]
+func (h *Handler) UpdateChannel(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	channel := models.NotificationChannel{Enabled: true}
+	if !readBody(c, &channel) {
+		return
+	}
+	channel.ID = id
+
+	updated, err := h.service.UpdateChannel(c.Request.Context(), &channel)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteChannel
+// @Summary Delete a notification channel
+// @Description Delete a notification channel and its rules
+// @Tags Notifications
+// @Produce  json
+// @Param id path string true "Channel ID"
+// @Success 204 "Successfully deleted channel"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/channels/{id} [delete]
+func (h *Handler) DeleteChannel(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteChannel(c.Request.Context(), id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestChannel
+// @Summary Send a test notification
+// @Description Send a sample notification to the channel right away, without retries
+// @Tags Notifications
+// @Produce  json
+// @Param id path string true "Channel ID"
+// @Success 204 "Test notification delivered"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 502 {object} map[string]string "Delivery failed"
+// @Router /notifications/channels/{id}/test [post]
+func (h *Handler) TestChannel(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.TestChannel(c.Request.Context(), id); err != nil {
+		status := statusFor(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRules
+// @Summary Get all notification rules
+// @Description Retrieve all notification rules
+// @Tags Notifications
+// @Produce  json
+// @Success 200 {array} models.NotificationRule "Successfully retrieved rules"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/rules [get]
+func (h *Handler) GetRules(c *gin.Context) {
+	rules, err := h.service.FindRules(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule
+// @Summary Create a notification rule
+// @Description Send the listed events (down, up, created, updated, deleted) to a channel
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Param rule body models.NotificationRule true "Rule data"
+// @Success 201 {object} models.NotificationRule "Successfully created rule"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/rules [post]
+func (h *Handler) CreateRule(c *gin.Context) {
+	rule := models.NotificationRule{Enabled: true}
+	if !readBody(c, &rule) {
+		return
+	}
+
+	created, err := h.service.CreateRule(c.Request.Context(), &rule)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateRule
+// @Summary Update a notification rule
+// @Description Replace a notification rule
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Rule ID"
+// @Param rule body models.NotificationRule true "Rule data"
+// @Success 200 {object} models.NotificationRule "Successfully updated rule"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/rules/{id} [patch]
+func (h *Handler) UpdateRule(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	rule := models.NotificationRule{Enabled: true}
+	if !readBody(c, &rule) {
+		return
+	}
+	rule.ID = id
+
+	updated, err := h.service.UpdateRule(c.Request.Context(), &rule)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule
+// @Summary Delete a notification rule
+// @Description Delete a notification rule
+// @Tags Notifications
+// @Produce  json
+// @Param id path string true "Rule ID"
+// @Success 204 "Successfully deleted rule"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/rules/{id} [delete]
+func (h *Handler) DeleteRule(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(c.Request.Context(), id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries
+// @Summary Get the notification delivery log
+// @Description Retrieve the most recent deliveries, newest first
+// @Tags Notifications
+// @Produce  json
+// @Param channelId query string false "Only deliveries to this channel"
+// @Param automationId query string false "Only deliveries about this automation"
+// @Param status query string false "pending, delivered or failed"
+// @Param limit query int false "Maximum number of deliveries" default(500)
+// @Success 200 {array} models.NotificationDelivery "Successfully retrieved deliveries"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/deliveries [get]
+func (h *Handler) GetDeliveries(c *gin.Context) {
+	var filter DeliveryFilter
+	var err error
+	if channelID := c.Query("channelId"); channelID != "" {
+		if filter.ChannelID, err = uuid.Parse(channelID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for channelId"})
+			return
+		}
+	}
+	if automationID := c.Query("automationId"); automationID != "" {
+		if filter.AutomationID, err = uuid.Parse(automationID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for automationId"})
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+	filter.Status = c.Query("status")
+
+	deliveries, err := h.service.FindDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readBody(c *gin.Context, v interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, v); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrChannelNotFound), errors.Is(err, ErrRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
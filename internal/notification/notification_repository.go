@@ -0,0 +1,213 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// dispatchLockKey is the Postgres advisory lock held while a replica sends
+// the pending deliveries, so that every notification is sent once.
+const dispatchLockKey int64 = 7_310_002
+
+type DeliveryFilter struct {
+	ChannelID    uuid.UUID
+	AutomationID uuid.UUID
+	Status       string
+	Limit        int
+}
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindChannels() ([]*models.NotificationChannel, error)
+	FindChannel(id uuid.UUID) (*models.NotificationChannel, error)
+	CreateChannel(channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	UpdateChannel(channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	DeleteChannel(id uuid.UUID) error
+	FindRules() ([]*models.NotificationRule, error)
+	FindRule(id uuid.UUID) (*models.NotificationRule, error)
+	CreateRule(rule *models.NotificationRule) (*models.NotificationRule, error)
+	UpdateRule(rule *models.NotificationRule) (*models.NotificationRule, error)
+	DeleteRule(id uuid.UUID) error
+	EnqueueDeliveries(deliveries []*models.NotificationDelivery) error
+	FindDeliveries(filter DeliveryFilter) ([]*models.NotificationDelivery, error)
+	FindDueDeliveries(now time.Time, limit int) ([]*models.NotificationDelivery, error)
+	MarkDelivered(id uint64, attempts int) error
+	MarkFailed(id uint64, attempts int, status string, nextAttemptAt time.Time, cause error) error
+	DeleteDeliveriesBefore(before time.Time) (int64, error)
+	WithDispatchLock(fn func() error) (bool, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindChannels() ([]*models.NotificationChannel, error) {
+	var channels []*models.NotificationChannel
+	err := r.DB.Order("name asc").Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (r *GormRepository) FindChannel(id uuid.UUID) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	err := r.DB.First(&channel, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *GormRepository) CreateChannel(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	err := r.DB.Create(channel).Error
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (r *GormRepository) UpdateChannel(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	err := r.DB.Save(channel).Error
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (r *GormRepository) DeleteChannel(id uuid.UUID) error {
+	return r.DB.Delete(&models.NotificationChannel{}, id).Error
+}
+
+func (r *GormRepository) FindRules() ([]*models.NotificationRule, error) {
+	var rules []*models.NotificationRule
+	err := r.DB.Order("name asc").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *GormRepository) FindRule(id uuid.UUID) (*models.NotificationRule, error) {
+	var rule models.NotificationRule
+	err := r.DB.First(&rule, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *GormRepository) CreateRule(rule *models.NotificationRule) (*models.NotificationRule, error) {
+	err := r.DB.Omit("Channel").Create(rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *GormRepository) UpdateRule(rule *models.NotificationRule) (*models.NotificationRule, error) {
+	err := r.DB.Omit("Channel").Save(rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *GormRepository) DeleteRule(id uuid.UUID) error {
+	return r.DB.Delete(&models.NotificationRule{}, id).Error
+}
+
+// EnqueueDeliveries queues the deliveries, skipping those already queued for
+// the same notification and channel.
+func (r *GormRepository) EnqueueDeliveries(deliveries []*models.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(deliveries).Error
+}
+
+func (r *GormRepository) FindDeliveries(filter DeliveryFilter) ([]*models.NotificationDelivery, error) {
+	query := r.DB.Order("id desc").Limit(filter.Limit)
+	if filter.ChannelID != uuid.Nil {
+		query = query.Where("channel_id = ?", filter.ChannelID)
+	}
+	if filter.AutomationID != uuid.Nil {
+		query = query.Where("automation_id = ?", filter.AutomationID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var deliveries []*models.NotificationDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *GormRepository) FindDueDeliveries(now time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	var deliveries []*models.NotificationDelivery
+	err := r.DB.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("id asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *GormRepository) MarkDelivered(id uint64, attempts int) error {
+	now := time.Now().UTC()
+	return r.DB.Model(&models.NotificationDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.DeliveryDelivered,
+		"attempts":     attempts,
+		"delivered_at": now,
+		"last_error":   "",
+	}).Error
+}
+
+func (r *GormRepository) MarkFailed(id uint64, attempts int, status string, nextAttemptAt time.Time, cause error) error {
+	return r.DB.Model(&models.NotificationDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      cause.Error(),
+	}).Error
+}
+
+// DeleteDeliveriesBefore removes finished deliveries created before before.
+func (r *GormRepository) DeleteDeliveriesBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("status <> ? AND created_at < ?", models.DeliveryPending, before).
+		Delete(&models.NotificationDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// WithDispatchLock runs fn holding the dispatch advisory lock as a lease, so
+// that no transaction stays open while notifications are sent. It reports
+// false without calling fn when another replica holds the lock.
+func (r *GormRepository) WithDispatchLock(fn func() error) (bool, error) {
+	return infra.WithSessionLock(r.DB, dispatchLockKey, fn)
+}
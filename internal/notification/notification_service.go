@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const maxDeliveryLimit = 500
+
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	ErrRuleNotFound    = errors.New("rule not found")
+	ErrInvalid         = errors.New("invalid notification settings")
+)
+
+type Service interface {
+	FindChannels(ctx context.Context) ([]*models.NotificationChannel, error)
+	FindChannel(ctx context.Context, id uuid.UUID) (*models.NotificationChannel, error)
+	CreateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	UpdateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	DeleteChannel(ctx context.Context, id uuid.UUID) error
+	TestChannel(ctx context.Context, id uuid.UUID) error
+	FindRules(ctx context.Context) ([]*models.NotificationRule, error)
+	CreateRule(ctx context.Context, rule *models.NotificationRule) (*models.NotificationRule, error)
+	UpdateRule(ctx context.Context, rule *models.NotificationRule) (*models.NotificationRule, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+	FindDeliveries(ctx context.Context, filter DeliveryFilter) ([]*models.NotificationDelivery, error)
+}
+
+type service struct {
+	repo    Repository
+	senders Senders
+}
+
+func NewService(repo Repository, senders Senders) Service {
+	return &service{
+		repo:    repo,
+		senders: senders,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), NewSenders(config.AppConfig.NotifyTimeout))
+}
+
+func (s *service) FindChannels(ctx context.Context) ([]*models.NotificationChannel, error) {
+	channels, err := s.repo.FindChannels()
+	if err != nil {
+		return nil, err
+	}
+	for i, channel := range channels {
+		channels[i] = channel.Redacted()
+	}
+	return channels, nil
+}
+
+func (s *service) FindChannel(ctx context.Context, id uuid.UUID) (*models.NotificationChannel, error) {
+	channel, err := s.findChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	return channel.Redacted(), nil
+}
+
+func (s *service) CreateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	channel.ID = uuid.UUID{} // reset ID
+	channel.CreatedAt = time.Now().UTC()
+	if err := channel.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	created, err := s.repo.CreateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return created.Redacted(), nil
+}
+
+// UpdateChannel replaces the channel. Credentials left empty keep their
+// current value, since they are never sent to clients, unless the channel
+// asks to clear them.
+func (s *service) UpdateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	current, err := s.findChannel(channel.ID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.ClearSecret && channel.Secret != "" || channel.ClearSMTPPassword && channel.SMTPPassword != "" {
+		return nil, fmt.Errorf("%w: a credential cannot be set and cleared at once", ErrInvalid)
+	}
+	if channel.Secret == "" && !channel.ClearSecret {
+		channel.Secret = current.Secret
+	}
+	if channel.SMTPPassword == "" && !channel.ClearSMTPPassword {
+		channel.SMTPPassword = current.SMTPPassword
+	}
+	channel.CreatedAt = current.CreatedAt
+	if err := channel.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	updated, err := s.repo.UpdateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return updated.Redacted(), nil
+}
+
+func (s *service) DeleteChannel(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.findChannel(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteChannel(id)
+}
+
+// TestChannel sends a sample notification straight to the channel, bypassing
+// rules and retries, and returns the delivery error.
+func (s *service) TestChannel(ctx context.Context, id uuid.UUID) error {
+	channel, err := s.findChannel(id)
+	if err != nil {
+		return err
+	}
+
+	notification := &Notification{
+		ID:    uuid.New(),
+		Event: "test",
+		Time:  time.Now().UTC(),
+		Actor: events.ActorFromContext(ctx),
+		Automation: &models.Automation{
+			Name:    "test",
+			URLPath: "test",
+		},
+	}
+	payload, err := models.JSON.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return s.senders.Send(channel, notification, payload)
+}
+
+func (s *service) FindRules(ctx context.Context) ([]*models.NotificationRule, error) {
+	return s.repo.FindRules()
+}
+
+func (s *service) CreateRule(ctx context.Context, rule *models.NotificationRule) (*models.NotificationRule, error) {
+	rule.ID = uuid.UUID{} // reset ID
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateRule(rule)
+}
+
+func (s *service) UpdateRule(ctx context.Context, rule *models.NotificationRule) (*models.NotificationRule, error) {
+	if _, err := s.repo.FindRule(rule.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateRule(rule)
+}
+
+func (s *service) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.repo.FindRule(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRuleNotFound
+		}
+		return err
+	}
+	return s.repo.DeleteRule(id)
+}
+
+func (s *service) FindDeliveries(ctx context.Context, filter DeliveryFilter) ([]*models.NotificationDelivery, error) {
+	if filter.Limit <= 0 || filter.Limit > maxDeliveryLimit {
+		filter.Limit = maxDeliveryLimit
+	}
+	return s.repo.FindDeliveries(filter)
+}
+
+func (s *service) validateRule(rule *models.NotificationRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, err := s.findChannel(rule.ChannelID); err != nil {
+		if errors.Is(err, ErrChannelNotFound) {
+			return fmt.Errorf("%w: channel %s does not exist", ErrInvalid, rule.ChannelID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) findChannel(id uuid.UUID) (*models.NotificationChannel, error) {
+	channel, err := s.repo.FindChannel(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChannelNotFound
+		}
+		return nil, err
+	}
+	return channel, nil
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *logRepository) UpdateChannel(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	saved := *channel
+	r.channels[channel.ID] = &saved
+	return channel, nil
+}
+
+func TestUpdateChannelKeepsOrClearsSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		clear      bool
+		wantSecret string
+		wantErr    error
+	}{
+		{name: "empty keeps", wantSecret: "s3cret"},
+		{name: "new replaces", secret: "n3w", wantSecret: "n3w"},
+		{name: "clear removes", clear: true, wantSecret: ""},
+		{name: "set and clear", secret: "n3w", clear: true, wantSecret: "s3cret", wantErr: ErrInvalid},
+	}
+	for _, test := range tests {
+		current := &models.NotificationChannel{ID: uuid.New(), Name: "Ops", Type: models.WebhookChannel,
+			URL: "https://example.com/hook", Secret: "s3cret", Enabled: true, CreatedAt: time.Now()}
+		repo := newLogRepository(current)
+		service := NewService(repo, NewSenders(time.Second))
+
+		update := *current
+		update.Secret, update.ClearSecret = test.secret, test.clear
+		_, err := service.UpdateChannel(context.Background(), &update)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: UpdateChannel() error = %v, want %v", test.name, err, test.wantErr)
+		}
+		if secret := repo.channels[current.ID].Secret; secret != test.wantSecret {
+			t.Errorf("%s: secret = %q, want %q", test.name, secret, test.wantSecret)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/healthcheck"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"time"
+)
+
+// healthNotificationSpace is the UUID namespace of the IDs of health
+// notifications.
+var healthNotificationSpace = uuid.MustParse("0c6f3d0e-5b9a-4e57-9d4f-2a1c8e7b6f10")
+
+// Notifier turns automation events and health changes into deliveries for
+// the channels whose rules match. It is registered with the outbox relay and
+// the prober; the Dispatcher sends what it queues.
+type Notifier struct {
+	repo Repository
+}
+
+func NewNotifier(repo Repository) *Notifier {
+	return &Notifier{
+		repo: repo,
+	}
+}
+
+func DefaultNotifier() *Notifier {
+	return NewNotifier(DefaultRepository())
+}
+
+func (n *Notifier) Publish(event *events.AutomationEvent) error {
+	if event.Replay || event.Automation == nil {
+		return nil
+	}
+
+	var kind string
+	switch event.Type {
+	case events.CreateEvent:
+		kind = models.NotifyCreated
+	case events.UpdateEvent:
+		kind = models.NotifyUpdated
+	case events.DeleteEvent:
+		kind = models.NotifyDeleted
+	default:
+		return nil
+	}
+
+	// the event ID keeps redelivered events from being notified twice
+	return n.notify(&Notification{
+		ID:         event.ID,
+		Event:      kind,
+		Time:       event.Time,
+		Actor:      event.Actor,
+		Automation: event.Automation,
+	})
+}
+
+func (n *Notifier) Close() error {
+	return nil
+}
+
+// Observe notifies when an automation goes down, and when it comes back up
+// after being down.
+func (n *Notifier) Observe(result *healthcheck.Result) error {
+	if !result.Changed() {
+		return nil
+	}
+
+	var kind string
+	switch {
+	case result.Status.Status == models.HealthUnhealthy:
+		kind = models.NotifyDown
+	case result.Status.Status == models.HealthHealthy && result.Previous == models.HealthUnhealthy:
+		kind = models.NotifyUp
+	default:
+		return nil
+	}
+
+	status := result.Status
+	return n.notify(&Notification{
+		ID:         healthNotificationID(result.Automation.ID, kind, result.CheckedAt),
+		Event:      kind,
+		Time:       result.CheckedAt,
+		Automation: result.Automation,
+		Health:     &status,
+	})
+}
+
+// healthNotificationID derives the ID of a health notification from what it
+// reports, so that reporting the same transition again, e.g. after a retry,
+// hits the dedupe index of the deliveries instead of notifying twice.
+func healthNotificationID(automationID uuid.UUID, kind string, checkedAt time.Time) uuid.UUID {
+	name := automationID.String() + "/" + kind + "/" + checkedAt.UTC().Format(time.RFC3339Nano)
+	return uuid.NewSHA1(healthNotificationSpace, []byte(name))
+}
+
+func (n *Notifier) notify(notification *Notification) error {
+	rules, err := n.repo.FindRules()
+	if err != nil {
+		return err
+	}
+
+	payload, err := models.JSON.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	queued := make(map[uuid.UUID]bool)
+	var deliveries []*models.NotificationDelivery
+	for _, rule := range rules {
+		if queued[rule.ChannelID] || !rule.Matches(notification.Event, notification.Automation) {
+			continue
+		}
+		queued[rule.ChannelID] = true
+		deliveries = append(deliveries, &models.NotificationDelivery{
+			NotificationID: notification.ID,
+			ChannelID:      rule.ChannelID,
+			RuleID:         rule.ID,
+			AutomationID:   notification.Automation.ID,
+			Event:          notification.Event,
+			Payload:        string(payload),
+			Status:         models.DeliveryPending,
+			CreatedAt:      now,
+			NextAttemptAt:  now,
+		})
+	}
+	return n.repo.EnqueueDeliveries(deliveries)
+}
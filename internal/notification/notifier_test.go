@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHealthNotificationIDIsDerivedFromTransition(t *testing.T) {
+	automationID := uuid.MustParse("6f1c1f5e-52d8-4e3c-9a61-3f3f0f3f9a02")
+	checkedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	id := healthNotificationID(automationID, models.NotifyDown, checkedAt)
+	again := healthNotificationID(automationID, models.NotifyDown, checkedAt.In(time.FixedZone("CEST", 7200)))
+	if again != id {
+		t.Errorf("ID of the same transition = %s, want %s", again, id)
+	}
+	for _, other := range []uuid.UUID{
+		healthNotificationID(uuid.New(), models.NotifyDown, checkedAt),
+		healthNotificationID(automationID, models.NotifyUp, checkedAt),
+		healthNotificationID(automationID, models.NotifyDown, checkedAt.Add(time.Second)),
+	} {
+		if other == id {
+			t.Errorf("ID of a different transition = %s, want it to differ", other)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification is the payload sent to channels and stored in the delivery
+// log.
+type Notification struct {
+	ID         uuid.UUID            `json:"id"`
+	Event      string               `json:"event"`
+	Time       time.Time            `json:"time"`
+	Actor      string               `json:"actor,omitempty"`
+	Automation *models.Automation   `json:"automation"`
+	Health     *models.HealthStatus `json:"health,omitempty"`
+}
+
+// Summary is the one-line, human-readable form of the notification.
+func (n *Notification) Summary() string {
+	name := n.Automation.Name
+	switch n.Event {
+	case models.NotifyDown:
+		summary := fmt.Sprintf("Automation %s is DOWN", name)
+		if n.Health != nil && n.Health.LastError != "" {
+			summary += ": " + n.Health.LastError
+		}
+		return summary
+	case models.NotifyUp:
+		return fmt.Sprintf("Automation %s is UP again", name)
+	default:
+		summary := fmt.Sprintf("Automation %s was %s", name, n.Event)
+		if n.Actor != "" {
+			summary += " by " + n.Actor
+		}
+		return summary
+	}
+}
+
+// Sender delivers a notification to one kind of channel.
+type Sender interface {
+	Send(channel *models.NotificationChannel, notification *Notification, payload []byte) error
+}
+
+// Senders holds the sender of every channel type.
+type Senders map[string]Sender
+
+func NewSenders(timeout time.Duration) Senders {
+	client := &http.Client{Timeout: timeout}
+	return Senders{
+		models.WebhookChannel: &WebhookSender{client: client},
+		models.SlackChannel:   &SlackSender{client: client},
+		models.SMTPChannel:    &SMTPSender{timeout: timeout},
+	}
+}
+
+func (s Senders) Send(channel *models.NotificationChannel, notification *Notification, payload []byte) error {
+	sender, ok := s[channel.Type]
+	if !ok {
+		return fmt.Errorf("no sender for channel type %q", channel.Type)
+	}
+	return sender.Send(channel, notification, payload)
+}
+
+// WebhookSender posts the JSON payload. When the channel has a secret, the
+// body is signed with HMAC-SHA256 in the X-Hub-Signature-256 header.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func (s *WebhookSender) Send(channel *models.NotificationChannel, notification *Notification, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Event", notification.Event)
+	req.Header.Set("X-Hub-Delivery", notification.ID.String())
+	if channel.Secret != "" {
+		req.Header.Set("X-Hub-Signature-256", "sha256="+Sign(channel.Secret, payload))
+	}
+	return post(s.client, req)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of payload, so that receivers can
+// verify webhook deliveries.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SlackSender posts an incoming-webhook message, which Slack and Mattermost
+// both accept.
+type SlackSender struct {
+	client *http.Client
+}
+
+func (s *SlackSender) Send(channel *models.NotificationChannel, notification *Notification, payload []byte) error {
+	body, err := models.JSON.Marshal(map[string]string{"text": notification.Summary()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(s.client, req)
+}
+
+func post(client *http.Client, req *http.Request) error {
+	req.Header.Set("User-Agent", "automation-hub-notifier")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// SMTPSender mails the summary with the JSON payload as the message body.
+// Authentication is only attempted when the channel has a username.
+type SMTPSender struct {
+	timeout time.Duration
+}
+
+func (s *SMTPSender) Send(channel *models.NotificationChannel, notification *Notification, payload []byte) error {
+	address := net.JoinHostPort(channel.SMTPHost, strconv.Itoa(channel.SMTPPort))
+	conn, err := net.DialTimeout("tcp", address, s.timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, channel.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: channel.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if channel.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", channel.SMTPUsername, channel.SMTPPassword, channel.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(channel.From); err != nil {
+		return err
+	}
+	for _, to := range channel.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message(channel, notification, payload)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// headerReplacer keeps values such as automation names and probe errors from
+// breaking out of a mail header.
+var headerReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+func message(channel *models.NotificationChannel, notification *Notification, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", headerReplacer.Replace(notification.Summary()))
+	fmt.Fprintf(&buf, "Date: %s\r\n", notification.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@automation-hub>\r\n", notification.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(notification.Summary() + "\r\n\r\n")
+	// SMTP limits the line length, so the payload is indented over many lines
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, payload, "", "  "); err != nil {
+		indented.Reset()
+		indented.Write(payload)
+	}
+	buf.WriteString(strings.ReplaceAll(indented.String(), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
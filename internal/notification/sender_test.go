@@ -0,0 +1,251 @@
+package notification
+
+import (
+	"automation-hub-backend/internal/models"
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testNotification() *Notification {
+	return &Notification{
+		ID:         uuid.MustParse("6f1c1f5e-52d8-4e3c-9a61-3f3f0f3f9a01"),
+		Event:      models.NotifyDown,
+		Time:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Automation: &models.Automation{Name: "Invoices"},
+		Health:     &models.HealthStatus{LastError: "connection refused"},
+	}
+}
+
+// capturedRequest is what a stub HTTP endpoint received.
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func stubEndpoint(t *testing.T, status int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		select {
+		case requests <- capturedRequest{header: r.Header.Clone(), body: body}:
+		default:
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("stub response"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	server, requests := stubEndpoint(t, http.StatusNoContent)
+	channel := &models.NotificationChannel{Type: models.WebhookChannel, URL: server.URL, Secret: "s3cret"}
+	payload := []byte(`{"event":"down"}`)
+
+	sender := &WebhookSender{client: server.Client()}
+	if err := sender.Send(channel, testNotification(), payload); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := <-requests
+	if string(got.body) != string(payload) {
+		t.Errorf("body = %s, want %s", got.body, payload)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(payload)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if signature := got.header.Get("X-Hub-Signature-256"); signature != want {
+		t.Errorf("X-Hub-Signature-256 = %q, want %q", signature, want)
+	}
+	if event := got.header.Get("X-Hub-Event"); event != models.NotifyDown {
+		t.Errorf("X-Hub-Event = %q, want %q", event, models.NotifyDown)
+	}
+	if delivery := got.header.Get("X-Hub-Delivery"); delivery != testNotification().ID.String() {
+		t.Errorf("X-Hub-Delivery = %q, want %q", delivery, testNotification().ID)
+	}
+}
+
+func TestWebhookSenderWithoutSecret(t *testing.T) {
+	server, requests := stubEndpoint(t, http.StatusOK)
+	channel := &models.NotificationChannel{Type: models.WebhookChannel, URL: server.URL}
+
+	sender := &WebhookSender{client: server.Client()}
+	if err := sender.Send(channel, testNotification(), []byte(`{}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if signature := (<-requests).header.Get("X-Hub-Signature-256"); signature != "" {
+		t.Errorf("X-Hub-Signature-256 = %q, want none without a secret", signature)
+	}
+}
+
+func TestWebhookSenderFailsOnErrorStatus(t *testing.T) {
+	server, _ := stubEndpoint(t, http.StatusBadGateway)
+	channel := &models.NotificationChannel{Type: models.WebhookChannel, URL: server.URL}
+
+	sender := &WebhookSender{client: server.Client()}
+	err := sender.Send(channel, testNotification(), []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "stub response") {
+		t.Errorf("Send() error = %v, want the status and body of the response", err)
+	}
+}
+
+func TestSlackSenderPostsSummary(t *testing.T) {
+	server, requests := stubEndpoint(t, http.StatusOK)
+	channel := &models.NotificationChannel{Type: models.SlackChannel, URL: server.URL}
+
+	sender := &SlackSender{client: server.Client()}
+	if err := sender.Send(channel, testNotification(), []byte(`{"ignored":true}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := <-requests
+	var body map[string]interface{}
+	if err := models.JSON.Unmarshal(got.body, &body); err != nil {
+		t.Fatalf("body %s is not JSON: %v", got.body, err)
+	}
+	want := "Automation Invoices is DOWN: connection refused"
+	if len(body) != 1 || body["text"] != want {
+		t.Errorf("body = %s, want only text %q", got.body, want)
+	}
+	if contentType := got.header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+}
+
+// smtpStub is a minimal SMTP server that accepts one message without
+// STARTTLS or authentication.
+type smtpStub struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &smtpStub{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go stub.serve()
+	return stub
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 stub ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 stub")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPSenderMailsSummaryAndPayload(t *testing.T) {
+	stub := newSMTPStub(t)
+	channel := &models.NotificationChannel{
+		Type:     models.SMTPChannel,
+		SMTPHost: "127.0.0.1",
+		SMTPPort: stub.port(),
+		From:     "hub@example.com",
+		To:       []string{"ops@example.com", "oncall@example.com"},
+	}
+
+	sender := &SMTPSender{timeout: 5 * time.Second}
+	if err := sender.Send(channel, testNotification(), []byte(`{"event":"down"}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	<-stub.done
+
+	if stub.from != "hub@example.com" {
+		t.Errorf("MAIL FROM = %q, want hub@example.com", stub.from)
+	}
+	if strings.Join(stub.to, ",") != "ops@example.com,oncall@example.com" {
+		t.Errorf("RCPT TO = %v, want both recipients", stub.to)
+	}
+	for _, want := range []string{
+		"Subject: Automation Invoices is DOWN: connection refused\r\n",
+		"Message-ID: <" + testNotification().ID.String() + "@automation-hub>\r\n",
+		"\"event\": \"down\"",
+	} {
+		if !strings.Contains(stub.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, stub.data)
+		}
+	}
+}
+
+func TestSMTPSenderFailsWhenServerIsDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	channel := &models.NotificationChannel{Type: models.SMTPChannel, SMTPHost: "127.0.0.1", SMTPPort: port,
+		From: "hub@example.com", To: []string{"ops@example.com"}}
+	sender := &SMTPSender{timeout: time.Second}
+	if err := sender.Send(channel, testNotification(), []byte(`{}`)); err == nil {
+		t.Errorf("Send() to closed port %d error = nil", port)
+	}
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"context"
+	"log"
+	"time"
+)
+
+// Relay drains the outbox table into the event publisher. Messages are sent
+// in insertion order and a failing message blocks the later messages of the
+// same automation until it is delivered, so consumers see every automation's
+// events in order and at least once.
+type Relay struct {
+	repo         Repository
+	publisher    events.Publisher
+	pollInterval time.Duration
+	batchSize    int
+	maxBackoff   time.Duration
+	retention    time.Duration
+}
+
+func NewRelay(repo Repository, publisher events.Publisher, pollInterval time.Duration, batchSize int,
+	maxBackoff time.Duration, retention time.Duration) *Relay {
+	return &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		maxBackoff:   maxBackoff,
+		retention:    retention,
+	}
+}
+
+func DefaultRelay() *Relay {
+	return NewRelay(DefaultRepository(), events.DefaultPublisher(), config.AppConfig.OutboxPollInterval,
+		config.AppConfig.OutboxBatchSize, config.AppConfig.OutboxMaxBackoff, config.AppConfig.OutboxRetention)
+}
+
+// AddPublisher makes the relay deliver every event to publisher as well, for
+// in-process consumers such as the notifier.
+func (r *Relay) AddPublisher(publisher events.Publisher) {
+	r.publisher = events.NewMultiPublisher(r.publisher, publisher)
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		if _, err := r.repo.WithRelayLock(func() error { return r.drain(ctx) }); err != nil {
+			log.Printf("Failed to drain outbox: %v", err)
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			r.cleanup()
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain publishes due messages until none is left. Every batch holds at most
+// one message per aggregate, so a failure only holds back the later messages
+// of its own aggregate.
+func (r *Relay) drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		messages, err := r.repo.FindPending(time.Now().UTC(), r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		published := 0
+		for _, message := range messages {
+			if errPublish := r.publish(message); errPublish != nil {
+				attempts := message.Attempts + 1
+				log.Printf("Failed to publish outbox message %d (attempt %d): %v", message.ID, attempts, errPublish)
+				nextAttemptAt := time.Now().UTC().Add(r.backoff(attempts))
+				if err := r.repo.MarkFailed(message.ID, attempts, nextAttemptAt, errPublish); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := r.repo.MarkPublished(message.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		if published == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *Relay) publish(message *models.OutboxMessage) error {
+	event, err := Decode(message)
+	if err != nil {
+		return err
+	}
+	return r.publisher.Publish(event)
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	return delay
+}
+
+func (r *Relay) cleanup() {
+	if r.retention <= 0 {
+		return
+	}
+	deleted, err := r.repo.DeletePublishedBefore(time.Now().UTC().Add(-r.retention))
+	if err != nil {
+		log.Printf("Failed to clean up outbox: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Removed %d published outbox messages", deleted)
+	}
+}
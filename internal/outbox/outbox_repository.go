@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// relayLockKey is the Postgres advisory lock held while a relay drains the
+// outbox, so that only one backend replica publishes at a time and the
+// per-automation ordering is preserved. It is held on a session rather than
+// in a transaction, since publishing may wait on the broker.
+const relayLockKey int64 = 7_310_001
+
+// snapshotLockKey is the Postgres advisory lock held while a replica decides
+// whether a periodic snapshot is due and queues it, so that one snapshot is
+// queued per interval however many replicas run the job.
+const snapshotLockKey int64 = 7_310_004
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	Enqueue(event *events.AutomationEvent) error
+	FindPending(now time.Time, limit int) ([]*models.OutboxMessage, error)
+	FindLatest(aggregateID string) (*models.OutboxMessage, error)
+	FindLatestPerAggregate() ([]*models.OutboxMessage, error)
+	FindLatestOfType(eventType string) (*models.OutboxMessage, error)
+	LatestID() (uint64, error)
+	MarkPublished(id uint64) error
+	MarkFailed(id uint64, attempts int, nextAttemptAt time.Time, cause error) error
+	DeletePublishedBefore(before time.Time) (int64, error)
+	WithRelayLock(fn func() error) (bool, error)
+	WithSnapshotLock(fn func(repo Repository) error) (bool, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) Enqueue(event *events.AutomationEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	message := &models.OutboxMessage{
+		AggregateID:   event.Key(),
+		EventType:     string(event.Type),
+		Payload:       string(payload),
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	return r.DB.Create(message).Error
+}
+
+// FindPending returns the messages that are due now and first in line for
+// their aggregate, oldest first. Later messages of an aggregate wait for the
+// earlier ones, and aggregates in backoff are left out altogether, so they
+// cannot fill the batch and starve the others.
+func (r *GormRepository) FindPending(now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	var messages []*models.OutboxMessage
+	err := r.DB.Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_messages earlier WHERE " +
+			"earlier.aggregate_id = outbox_messages.aggregate_id AND earlier.published_at IS NULL AND " +
+			"earlier.id < outbox_messages.id)").
+		Order("id asc").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *GormRepository) FindLatest(aggregateID string) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	err := r.DB.Where("aggregate_id = ?", aggregateID).Order("id desc").First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *GormRepository) FindLatestPerAggregate() ([]*models.OutboxMessage, error) {
+	var messages []*models.OutboxMessage
+	err := r.DB.Raw("SELECT DISTINCT ON (aggregate_id) * FROM outbox_messages ORDER BY aggregate_id, id DESC").
+		Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *GormRepository) FindLatestOfType(eventType string) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	err := r.DB.Where("event_type = ?", eventType).Order("id desc").First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// LatestID returns the ID of the newest message, or 0 when there is none.
+// Since every change to the automations enqueues a message in its
+// transaction, a new ID means the automations changed. The reverse does not
+// hold: a transaction that took a lower ID may commit after a newer one.
+func (r *GormRepository) LatestID() (uint64, error) {
+	var id uint64
+	err := r.DB.Model(&models.OutboxMessage{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (r *GormRepository) MarkPublished(id uint64) error {
+	now := time.Now().UTC()
+	return r.DB.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"published_at": now,
+		"last_error":   "",
+	}).Error
+}
+
+func (r *GormRepository) MarkFailed(id uint64, attempts int, nextAttemptAt time.Time, cause error) error {
+	return r.DB.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      cause.Error(),
+	}).Error
+}
+
+// DeletePublishedBefore removes delivered messages older than before. The
+// latest message of every aggregate is kept so it can always be replayed.
+func (r *GormRepository) DeletePublishedBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("published_at IS NOT NULL AND published_at < ?", before).
+		Where("id NOT IN (SELECT MAX(id) FROM outbox_messages GROUP BY aggregate_id)").
+		Delete(&models.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
+
+// WithRelayLock runs fn holding the relay advisory lock as a lease. It
+// reports false without calling fn when another replica holds the lock.
+func (r *GormRepository) WithRelayLock(fn func() error) (bool, error) {
+	return infra.WithSessionLock(r.DB, relayLockKey, fn)
+}
+
+// WithSnapshotLock runs fn inside a transaction holding the snapshot advisory
+// lock. It reports false without calling fn when another replica holds the
+// lock.
+func (r *GormRepository) WithSnapshotLock(fn func(repo Repository) error) (bool, error) {
+	acquired := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", snapshotLockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		return fn(r.WithTx(tx))
+	})
+	return acquired, err
+}
+
+// Decode restores the event stored in an outbox message.
+func Decode(message *models.OutboxMessage) (*events.AutomationEvent, error) {
+	var event events.AutomationEvent
+	if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
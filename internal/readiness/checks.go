@@ -0,0 +1,142 @@
+package readiness
+
+import (
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/infra"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"os"
+	"sync"
+)
+
+// Checker verifies that one dependency of the hub is usable.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// DefaultCheckers returns the checks of the dependencies the configuration
+// uses.
+func DefaultCheckers() []Checker {
+	checkers := []Checker{
+		&MigrationChecker{},
+		&DatabaseChecker{},
+		&DirectoryChecker{name: "imageDir", dir: config.AppConfig.ImageSaveDir},
+	}
+	if config.AppConfig.EventTransport == events.KafkaTransport {
+		checkers = append(checkers, NewKafkaChecker(config.AppConfig.Brokers, config.AppConfig.Topic))
+	}
+	return checkers
+}
+
+// MigrationChecker fails while the schema migrations are running, which the
+// hub does at startup while already serving the probes.
+type MigrationChecker struct{}
+
+func (c *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	if infra.MigrationsRunning() {
+		return errors.New("migrations are running")
+	}
+	return nil
+}
+
+type DatabaseChecker struct{}
+
+func (c *DatabaseChecker) Name() string {
+	return "database"
+}
+
+func (c *DatabaseChecker) Check(ctx context.Context) error {
+	db, err := infra.OpenedDB()
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// DirectoryChecker verifies that files can be created in a directory.
+type DirectoryChecker struct {
+	name string
+	dir  string
+}
+
+func (c *DirectoryChecker) Name() string {
+	return c.name
+}
+
+func (c *DirectoryChecker) Check(ctx context.Context) error {
+	file, err := os.CreateTemp(c.dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	name := file.Name()
+	if err := file.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(name)
+}
+
+// KafkaChecker refreshes the metadata of the event topic, which fails when no
+// broker is reachable or the topic has no partitions.
+type KafkaChecker struct {
+	brokers []string
+	topic   string
+
+	mu     sync.Mutex
+	client sarama.Client
+}
+
+func NewKafkaChecker(brokers []string, topic string) *KafkaChecker {
+	return &KafkaChecker{
+		brokers: brokers,
+		topic:   topic,
+	}
+}
+
+func (c *KafkaChecker) Name() string {
+	return "kafka"
+}
+
+// Check does not honour ctx itself; the sarama client is given timeouts that
+// match the check timeout instead.
+func (c *KafkaChecker) Check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil || c.client.Closed() {
+		cfg := sarama.NewConfig()
+		cfg.Metadata.Retry.Max = 0
+		cfg.Metadata.Full = false
+		cfg.Net.DialTimeout = checkTimeout
+		cfg.Net.ReadTimeout = checkTimeout
+		client, err := sarama.NewClient(c.brokers, cfg)
+		if err != nil {
+			return err
+		}
+		c.client = client
+	}
+
+	if err := c.client.RefreshMetadata(c.topic); err != nil {
+		return err
+	}
+	partitions, err := c.client.Partitions(c.topic)
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return fmt.Errorf("topic %s has no partitions", c.topic)
+	}
+	return nil
+}
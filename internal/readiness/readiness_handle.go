@@ -0,0 +1,123 @@
+package readiness
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds how long readiness waits for a single dependency.
+const checkTimeout = 5 * time.Second
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                  `json:"status"`
+	Checks map[string]*CheckResult `json:"checks"`
+}
+
+type Handler struct {
+	checkers []Checker
+}
+
+func NewHandler(checkers []Checker) *Handler {
+	return &Handler{
+		checkers: checkers,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultCheckers())
+}
+
+// Liveness
+// @Summary Liveness probe
+// @Description Reports that the process is up and serving requests
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} map[string]string "Alive"
+// @Router /healthz [get]
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusUp})
+}
+
+// Readiness
+// @Summary Readiness probe
+// @Description Checks the database, Kafka, the image directory and the migrations
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} readiness.Report "Ready"
+// @Failure 503 {object} readiness.Report "Not ready"
+// @Router /readyz [get]
+func (h *Handler) Readiness(c *gin.Context) {
+	report := h.check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func (h *Handler) check(ctx context.Context) *Report {
+	report := &Report{Status: StatusReady, Checks: make(map[string]*CheckResult, len(h.checkers))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, checker := range h.checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+			result := run(ctx, checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+			if result.Status != StatusUp {
+				report.Status = StatusNotReady
+			}
+		}(checker)
+	}
+	wg.Wait()
+	return report
+}
+
+func run(ctx context.Context, checker Checker) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := &CheckResult{Status: StatusUp, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package revision
+
+import (
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"context"
+)
+
+// New describes a change that took an automation from before to after. A nil
+// before marks a newly created automation.
+func New(ctx context.Context, action string, before *models.Automation, after *models.Automation) *models.AutomationRevision {
+	snapshot := models.NewAutomationSnapshot(after)
+
+	var previous *models.AutomationSnapshot
+	if before != nil {
+		previous = models.NewAutomationSnapshot(before)
+	}
+	return &models.AutomationRevision{
+		AutomationID: after.ID,
+		Action:       action,
+		Actor:        events.ActorFromContext(ctx),
+		Snapshot:     snapshot,
+		Changes:      snapshot.Diff(previous),
+	}
+}
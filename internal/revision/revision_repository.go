@@ -0,0 +1,86 @@
+package revision
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository stores the revision history of automations. Revisions are
+// recorded in the transaction of the change they describe.
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	WithContext(ctx context.Context) Repository
+	Record(revision *models.AutomationRevision) error
+	FindByAutomation(automationID uuid.UUID) ([]*models.AutomationRevision, error)
+	Find(automationID uuid.UUID, revision int) (*models.AutomationRevision, error)
+	DeleteByAutomation(automationID uuid.UUID) error
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) WithContext(ctx context.Context) Repository {
+	return NewGormRepository(r.DB.WithContext(ctx))
+}
+
+// Record stores revision as the next revision of its automation. Two changes
+// racing for the same number fail on the unique index rather than
+// interleaving.
+func (r *GormRepository) Record(revision *models.AutomationRevision) error {
+	var latest int
+	err := r.DB.Model(&models.AutomationRevision{}).
+		Where("automation_id = ?", revision.AutomationID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	revision.Revision = latest + 1
+	return r.DB.Create(revision).Error
+}
+
+// FindByAutomation returns the revisions of an automation, newest first.
+func (r *GormRepository) FindByAutomation(automationID uuid.UUID) ([]*models.AutomationRevision, error) {
+	var revisions []*models.AutomationRevision
+	err := r.DB.Where("automation_id = ?", automationID).Order("revision desc").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *GormRepository) Find(automationID uuid.UUID, revision int) (*models.AutomationRevision, error) {
+	var found models.AutomationRevision
+	err := r.DB.First(&found, "automation_id = ? AND revision = ?", automationID, revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (r *GormRepository) DeleteByAutomation(automationID uuid.UUID) error {
+	return r.DB.Where("automation_id = ?", automationID).Delete(&models.AutomationRevision{}).Error
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"automation-hub-backend/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// actorHeader names the caller on whose behalf a change is made. It is set by
+// the dashboard or the proxy in front of the hub and ends up in the events.
+const actorHeader = "X-Actor"
+
+func actorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := c.GetHeader(actorHeader); actor != "" {
+			c.Request = c.Request.WithContext(events.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
@@ -2,25 +2,67 @@ package router
 
 import (
 	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/metrics"
+	"automation-hub-backend/internal/readiness"
+	"automation-hub-backend/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync/atomic"
 )
 
-func Initialize() error {
-	// initialize Router
-	router := gin.Default()
+// Server serves /healthz and /readyz from the moment the process starts and
+// the API once it is mounted, so that a slow or unreachable database shows
+// up as not ready instead of a process that does not answer.
+type Server struct {
+	readiness *readiness.Handler
+	startup   *gin.Engine
+	api       atomic.Pointer[gin.Engine]
+}
+
+func NewServer() *Server {
+	s := &Server{readiness: readiness.DefaultHandler()}
+
+	s.startup = newEngine()
+	s.startup.GET("/healthz", s.readiness.Liveness)
+	s.startup.GET("/readyz", s.readiness.Readiness)
+	s.startup.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "The hub is starting"})
+	})
+	return s
+}
+
+// Run serves requests on the configured port until the server fails.
+func (s *Server) Run() error {
+	return http.ListenAndServe(config.AppConfig.ServerPort, s)
+}
+
+// Mount builds the API routes and serves them from now on. It needs the
+// database to be open.
+func (s *Server) Mount() error {
+	router := newEngine()
+	router.Use(actorMiddleware())
 
 	// initialize routes
-	err := initializeRoutes(router)
+	err := initializeRoutes(router, s.readiness)
 	if err != nil {
 		return err
 	}
 
-	// run server
-	port := config.AppConfig.ServerPort
-	err = router.Run(port)
-	if err != nil {
-		return err
+	s.api.Store(router)
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if api := s.api.Load(); api != nil {
+		api.ServeHTTP(w, r)
+		return
 	}
+	s.startup.ServeHTTP(w, r)
+}
 
-	return nil
+func newEngine() *gin.Engine {
+	router := gin.Default()
+	router.Use(tracing.Middleware())
+	router.Use(metrics.Middleware())
+	return router
 }
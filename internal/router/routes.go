@@ -2,25 +2,135 @@ package router
 
 import (
 	"automation-hub-backend/docs"
+	"automation-hub-backend/internal/admin"
 	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/category"
 	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/environment"
+	"automation-hub-backend/internal/gateway"
+	"automation-hub-backend/internal/healthcheck"
+	"automation-hub-backend/internal/maintenance"
+	"automation-hub-backend/internal/metrics"
+	"automation-hub-backend/internal/notification"
+	"automation-hub-backend/internal/readiness"
+	"automation-hub-backend/internal/tag"
+	"automation-hub-backend/internal/target"
+	"automation-hub-backend/internal/uptime"
+	"automation-hub-backend/internal/workspace"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func initializeRoutes(router *gin.Engine) error {
+func initializeRoutes(router *gin.Engine, readinessHandler *readiness.Handler) error {
 	relativePathV1 := config.AppConfig.BaseUrl + "/v1"
 	docs.SwaggerInfo.BasePath = relativePathV1
 	v1 := router.Group(relativePathV1)
 	{
+		workspaceHandler := workspace.DefaultHandler()
+		err := initializeWorkspaceRoutes(v1, workspaceHandler)
+		if err != nil {
+			return err
+		}
+
 		autoHandler := automation.DefaultHandler()
-		err := initializeAutomationsRoutes(v1, autoHandler)
+		targetHandler := target.DefaultHandler()
+		environmentHandler := environment.DefaultHandler()
+		maintenanceHandler := maintenance.DefaultHandler()
+		tagHandler := tag.DefaultHandler()
+		healthCheckHandler := healthcheck.DefaultHandler()
+		uptimeHandler := uptime.DefaultHandler()
+
+		// Automations and everything below them live in a workspace. The
+		// routes outside /workspaces/:ws serve the default workspace.
+		scopes := []*gin.RouterGroup{
+			v1.Group("", workspaceHandler.Scope()),
+			v1.Group("/workspaces/:ws", workspaceHandler.Scope()),
+		}
+		for _, scope := range scopes {
+			err = initializeAutomationsRoutes(scope, autoHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeTargetRoutes(scope, targetHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeEnvironmentRoutes(scope, environmentHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeMaintenanceRoutes(scope, maintenanceHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeAutomationTagRoutes(scope, tagHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeHealthCheckRoutes(scope, healthCheckHandler)
+			if err != nil {
+				return err
+			}
+
+			err = initializeUptimeRoutes(scope, uptimeHandler)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = initializeTagRoutes(v1, tagHandler)
+		if err != nil {
+			return err
+		}
+
+		categoryHandler := category.DefaultHandler()
+		err = initializeCategoryRoutes(v1, categoryHandler)
+		if err != nil {
+			return err
+		}
+
+		notificationHandler := notification.DefaultHandler()
+		err = initializeNotificationRoutes(v1, notificationHandler)
+		if err != nil {
+			return err
+		}
+
+		adminHandler := admin.DefaultHandler()
+		err = initializeAdminRoutes(v1, adminHandler)
+		if err != nil {
+			return err
+		}
+
+		gatewayHandler := gateway.DefaultHandler()
+		err = initializeGatewayRoutes(v1, gatewayHandler)
 		if err != nil {
 			return err
 		}
 	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+
+	router.GET("/healthz", readinessHandler.Liveness)
+	router.GET("/readyz", readinessHandler.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	return nil
+}
+
+func initializeWorkspaceRoutes(apiVersion *gin.RouterGroup, workspaceHandler *workspace.Handler) error {
+	workspaces := apiVersion.Group("/workspaces")
+	{
+		workspaces.GET("", workspaceHandler.GetAll)
+		workspaces.POST("", workspaceHandler.Create)
+		workspaces.GET("/:ws", workspaceHandler.Get)
+		workspaces.PATCH("/:ws", workspaceHandler.Update)
+		workspaces.DELETE("/:ws", workspaceHandler.Delete)
+	}
+
 	return nil
 }
 
@@ -29,12 +139,144 @@ func initializeAutomationsRoutes(apiVersion *gin.RouterGroup, autoHandler *autom
 	{
 		automations.GET("/swap/:id1/:id2", autoHandler.SwapPosition)
 		automations.GET("/", autoHandler.GetAll)
+		automations.GET("/trash", autoHandler.Trash)
+		automations.GET("/resolve/:slug", autoHandler.Resolve)
 		automations.GET("/:id", autoHandler.GetByID)
 		automations.POST("/", autoHandler.Create)
 		automations.PATCH("/", autoHandler.Update)
 		automations.DELETE("/:id", autoHandler.DeleteByID)
+		automations.POST("/:id/restore", autoHandler.Restore)
+		automations.PUT("/:id/state", autoHandler.SetState)
+		automations.GET("/:id/revisions", autoHandler.Revisions)
+		automations.GET("/:id/revisions/:rev", autoHandler.Revision)
+		automations.POST("/:id/revisions/:rev/restore", autoHandler.RollbackRevision)
 		automations.GET("/images/:imageName", autoHandler.ImageHandler)
 	}
 
 	return nil
 }
+
+func initializeTargetRoutes(apiVersion *gin.RouterGroup, targetHandler *target.Handler) error {
+	targets := apiVersion.Group("/automation/:id/targets")
+	{
+		targets.GET("/", targetHandler.GetAll)
+		targets.POST("/", targetHandler.Create)
+		targets.PATCH("/:targetId", targetHandler.Update)
+		targets.DELETE("/:targetId", targetHandler.Delete)
+	}
+
+	return nil
+}
+
+func initializeEnvironmentRoutes(apiVersion *gin.RouterGroup, environmentHandler *environment.Handler) error {
+	environments := apiVersion.Group("/automation/:id/environments")
+	{
+		environments.GET("", environmentHandler.GetAll)
+		environments.PUT("/:env", environmentHandler.Put)
+		environments.DELETE("/:env", environmentHandler.Delete)
+		environments.POST("/:env/promote", environmentHandler.Promote)
+	}
+
+	return nil
+}
+
+func initializeMaintenanceRoutes(apiVersion *gin.RouterGroup, maintenanceHandler *maintenance.Handler) error {
+	windows := apiVersion.Group("/automation/:id/maintenance-windows")
+	{
+		windows.GET("", maintenanceHandler.GetAll)
+		windows.POST("", maintenanceHandler.Create)
+		windows.DELETE("/:windowId", maintenanceHandler.Delete)
+	}
+
+	return nil
+}
+
+func initializeTagRoutes(apiVersion *gin.RouterGroup, tagHandler *tag.Handler) error {
+	tags := apiVersion.Group("/tags")
+	{
+		tags.GET("", tagHandler.GetAll)
+		tags.POST("", tagHandler.Create)
+		tags.PATCH("/:id", tagHandler.Update)
+		tags.DELETE("/:id", tagHandler.Delete)
+	}
+
+	return nil
+}
+
+func initializeAutomationTagRoutes(apiVersion *gin.RouterGroup, tagHandler *tag.Handler) error {
+	apiVersion.PUT("/automation/:id/tags", tagHandler.SetAutomationTags)
+
+	return nil
+}
+
+func initializeCategoryRoutes(apiVersion *gin.RouterGroup, categoryHandler *category.Handler) error {
+	categories := apiVersion.Group("/categories")
+	{
+		categories.GET("", categoryHandler.GetAll)
+		categories.POST("", categoryHandler.Create)
+		categories.PATCH("/:id", categoryHandler.Update)
+		categories.DELETE("/:id", categoryHandler.Delete)
+	}
+
+	return nil
+}
+
+func initializeHealthCheckRoutes(apiVersion *gin.RouterGroup, healthCheckHandler *healthcheck.Handler) error {
+	healthChecks := apiVersion.Group("/automation/:id/healthcheck")
+	{
+		healthChecks.GET("", healthCheckHandler.Get)
+		healthChecks.PUT("", healthCheckHandler.Set)
+		healthChecks.DELETE("", healthCheckHandler.Delete)
+	}
+
+	return nil
+}
+
+func initializeUptimeRoutes(apiVersion *gin.RouterGroup, uptimeHandler *uptime.Handler) error {
+	apiVersion.GET("/automation/:id/uptime", uptimeHandler.Uptime)
+
+	return nil
+}
+
+func initializeNotificationRoutes(apiVersion *gin.RouterGroup, notificationHandler *notification.Handler) error {
+	notifications := apiVersion.Group("/notifications")
+	{
+		notifications.GET("/channels", notificationHandler.GetChannels)
+		notifications.POST("/channels", notificationHandler.CreateChannel)
+		notifications.GET("/channels/:id", notificationHandler.GetChannel)
+		notifications.PATCH("/channels/:id", notificationHandler.UpdateChannel)
+		notifications.DELETE("/channels/:id", notificationHandler.DeleteChannel)
+		notifications.POST("/channels/:id/test", notificationHandler.TestChannel)
+		notifications.GET("/rules", notificationHandler.GetRules)
+		notifications.POST("/rules", notificationHandler.CreateRule)
+		notifications.PATCH("/rules/:id", notificationHandler.UpdateRule)
+		notifications.DELETE("/rules/:id", notificationHandler.DeleteRule)
+		notifications.GET("/deliveries", notificationHandler.GetDeliveries)
+	}
+
+	return nil
+}
+
+func initializeAdminRoutes(apiVersion *gin.RouterGroup, adminHandler *admin.Handler) error {
+	adminGroup := apiVersion.Group("/admin")
+	{
+		adminGroup.POST("/snapshot", adminHandler.Snapshot)
+		adminGroup.POST("/replay", adminHandler.Replay)
+		adminGroup.POST("/replay/:id", adminHandler.ReplayAutomation)
+	}
+
+	return nil
+}
+
+func initializeGatewayRoutes(apiVersion *gin.RouterGroup, gatewayHandler *gateway.Handler) error {
+	gatewayGroup := apiVersion.Group("/gateway")
+	{
+		gatewayGroup.GET("/config", gatewayHandler.Config)
+		if config.AppConfig.GatewayEnabled {
+			gatewayGroup.GET("/status", gatewayHandler.Status)
+			gatewayGroup.POST("/sync", gatewayHandler.Sync)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package tag
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// AutomationTags is the body of a request replacing the tags of an automation.
+type AutomationTags struct {
+	Tags []string `json:"tags"`
+}
+
+// GetAll
+// @Summary Get all tags
+// @Description Retrieve every tag, ordered by name
+// @Tags Tags
+// @Produce  json
+// @Success 200 {array} models.Tag "Successfully retrieved tags"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	tags, err := h.service.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Create
+// @Summary Create a tag
+// @Description Create a tag; names are lowercase and unique
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param tag body models.Tag true "Tag data"
+// @Success 201 {object} models.Tag "Successfully created tag"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags [post]
+func (h *Handler) Create(c *gin.Context) {
+	var tag models.Tag
+	if !readBody(c, &tag) {
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), &tag)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Update
+// @Summary Rename a tag
+// @Description Rename a tag; the automations carrying it are republished
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Param tag body models.Tag true "Tag data"
+// @Success 200 {object} models.Tag "Successfully updated tag"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags/{id} [patch]
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var tag models.Tag
+	if !readBody(c, &tag) {
+		return
+	}
+	tag.ID = id
+
+	updated, err := h.service.Update(c.Request.Context(), &tag)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every automation carrying it
+// @Tags Tags
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Success 204 "Successfully deleted tag"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetAutomationTags
+// @Summary Set the tags of an automation
+// @Description Replace the tags of an automation with the named tags, which must exist
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param tags body AutomationTags true "Tag names"
+// @Success 200 {object} models.Automation "Successfully tagged automation"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/tags [put]
+func (h *Handler) SetAutomationTags(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var body AutomationTags
+	if !readBody(c, &body) {
+		return
+	}
+
+	updated, err := h.service.SetAutomationTags(c.Request.Context(), automationID, body.Tags)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for " + param})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readBody(c *gin.Context, value interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound), errors.Is(err, ErrTagNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidTag):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTagExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
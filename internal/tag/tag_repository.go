@@ -0,0 +1,109 @@
+package tag
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindAll() ([]*models.Tag, error)
+	FindByID(id uuid.UUID) (*models.Tag, error)
+	FindByName(name string) (*models.Tag, error)
+	FindByNames(names []string) ([]*models.Tag, error)
+	Create(tag *models.Tag) (*models.Tag, error)
+	Update(tag *models.Tag) (*models.Tag, error)
+	Delete(id uuid.UUID) error
+	ReplaceAutomationTags(automation *models.Automation, tags []*models.Tag) error
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindAll() ([]*models.Tag, error) {
+	var tags []*models.Tag
+	err := r.DB.Order("name asc").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *GormRepository) FindByID(id uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.DB.First(&tag, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *GormRepository) FindByName(name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.DB.First(&tag, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *GormRepository) FindByNames(names []string) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.DB.Where("name IN ?", names).Order("name asc").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *GormRepository) Create(tag *models.Tag) (*models.Tag, error) {
+	err := r.DB.Create(tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *GormRepository) Update(tag *models.Tag) (*models.Tag, error) {
+	err := r.DB.Save(tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Delete removes the tag from every automation and then the tag itself.
+func (r *GormRepository) Delete(id uuid.UUID) error {
+	if err := r.DB.Exec("DELETE FROM automation_tags WHERE tag_id = ?", id).Error; err != nil {
+		return err
+	}
+	return r.DB.Delete(&models.Tag{}, id).Error
+}
+
+func (r *GormRepository) ReplaceAutomationTags(automation *models.Automation, tags []*models.Tag) error {
+	return r.DB.Model(automation).Omit("Tags.*").Association("Tags").Replace(tags)
+}
@@ -0,0 +1,228 @@
+package tag
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"sort"
+)
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagExists          = errors.New("a tag with this name already exists")
+	ErrInvalidTag         = errors.New("invalid tag")
+)
+
+type Service interface {
+	FindAll(ctx context.Context) ([]*models.Tag, error)
+	Create(ctx context.Context, tag *models.Tag) (*models.Tag, error)
+	Update(ctx context.Context, tag *models.Tag) (*models.Tag, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	SetAutomationTags(ctx context.Context, automationID uuid.UUID, names []string) (*models.Automation, error)
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+	outbox         outbox.Repository
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+		outbox:         outboxRepo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context) ([]*models.Tag, error) {
+	return s.repo.FindAll()
+}
+
+func (s *service) Create(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	tag.ID = uuid.UUID{} // reset ID
+	if err := tag.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+	}
+
+	var created *models.Tag
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if err := ensureNameFree(txRepo, tag); err != nil {
+			return err
+		}
+		var err error
+		created, err = txRepo.Create(tag)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update renames a tag. The automations carrying it are republished so that
+// consumers see the new name.
+func (s *service) Update(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	if err := tag.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+	}
+
+	var updated *models.Tag
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		current, err := findTag(txRepo, tag.ID)
+		if err != nil {
+			return err
+		}
+		if err := ensureNameFree(txRepo, tag); err != nil {
+			return err
+		}
+		tagged, err := s.automationRepo.WithTx(tx).Find(automation.Filter{Tags: []string{current.Name}})
+		if err != nil {
+			return err
+		}
+
+		updated, err = txRepo.Update(tag)
+		if err != nil {
+			return err
+		}
+		return s.publishChanges(ctx, tx, tagged)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes a tag from every automation carrying it and republishes
+// those automations.
+func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		current, err := findTag(txRepo, id)
+		if err != nil {
+			return err
+		}
+		tagged, err := s.automationRepo.WithTx(tx).Find(automation.Filter{Tags: []string{current.Name}})
+		if err != nil {
+			return err
+		}
+
+		if err := txRepo.Delete(id); err != nil {
+			return err
+		}
+		return s.publishChanges(ctx, tx, tagged)
+	})
+}
+
+// SetAutomationTags replaces the tags of an automation with the named tags,
+// which must all exist.
+func (s *service) SetAutomationTags(ctx context.Context, automationID uuid.UUID, names []string) (*models.Automation, error) {
+	var updated *models.Automation
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		current, err := findAutomation(s.automationRepo.WithTx(tx), automationID)
+		if err != nil {
+			return err
+		}
+
+		tags, err := txRepo.FindByNames(names)
+		if err != nil {
+			return err
+		}
+		if missing := missingNames(names, tags); len(missing) > 0 {
+			return fmt.Errorf("%w: unknown tags %v", ErrInvalidTag, missing)
+		}
+
+		if err := txRepo.ReplaceAutomationTags(current, tags); err != nil {
+			return err
+		}
+		updated, err = findAutomation(s.automationRepo.WithTx(tx), automationID)
+		if err != nil {
+			return err
+		}
+		return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// publishChanges queues an update event for every automation, reloaded so the
+// events carry their tags as they are now.
+func (s *service) publishChanges(ctx context.Context, tx *gorm.DB, automations []*models.Automation) error {
+	for _, changed := range automations {
+		current, err := findAutomation(s.automationRepo.WithTx(tx), changed.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, current)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureNameFree(repo Repository, tag *models.Tag) error {
+	existing, err := repo.FindByName(tag.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != tag.ID {
+		return ErrTagExists
+	}
+	return nil
+}
+
+func findTag(repo Repository, id uuid.UUID) (*models.Tag, error) {
+	tag, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func findAutomation(repo automation.Repository, id uuid.UUID) (*models.Automation, error) {
+	current, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+func missingNames(names []string, tags []*models.Tag) []string {
+	found := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		found[tag.Name] = true
+	}
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,188 @@
+package target
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// GetAll
+// @Summary Get the targets of an automation
+// @Description Retrieve the additional upstream targets of an automation
+// @Tags Targets
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Success 200 {array} models.UpstreamTarget "Successfully retrieved targets"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/targets [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	targets, err := h.service.FindAll(c.Request.Context(), automationID)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// Create
+// @Summary Add a target to an automation
+// @Description Add an upstream target with an optional weight and backup flag
+// @Tags Targets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param target body models.UpstreamTarget true "Target data"
+// @Success 201 {object} models.UpstreamTarget "Successfully created target"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/targets [post]
+func (h *Handler) Create(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	target, ok := readTarget(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), automationID, target)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Update
+// @Summary Update a target of an automation
+// @Description Update an upstream target of an automation
+// @Tags Targets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param targetId path string true "Target ID"
+// @Param target body models.UpstreamTarget true "Target data"
+// @Success 200 {object} models.UpstreamTarget "Successfully updated target"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/targets/{targetId} [patch]
+func (h *Handler) Update(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseID(c, "targetId")
+	if !ok {
+		return
+	}
+	target, ok := readTarget(c)
+	if !ok {
+		return
+	}
+	target.ID = targetID
+
+	updated, err := h.service.Update(c.Request.Context(), automationID, target)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete
+// @Summary Remove a target from an automation
+// @Description Remove an upstream target from an automation
+// @Tags Targets
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param targetId path string true "Target ID"
+// @Success 204 "Successfully deleted target"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/targets/{targetId} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	automationID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseID(c, "targetId")
+	if !ok {
+		return
+	}
+
+	err := h.service.Delete(c.Request.Context(), automationID, targetID)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format for " + param})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func readTarget(c *gin.Context) (*models.UpstreamTarget, bool) {
+	var target models.UpstreamTarget
+
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return nil, false
+	}
+
+	if err := models.JSON.Unmarshal(body, &target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &target, true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound), errors.Is(err, ErrTargetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidTarget):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
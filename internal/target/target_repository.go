@@ -0,0 +1,77 @@
+package target
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindByAutomation(automationID uuid.UUID) ([]*models.UpstreamTarget, error)
+	FindByID(automationID uuid.UUID, id uuid.UUID) (*models.UpstreamTarget, error)
+	Create(target *models.UpstreamTarget) (*models.UpstreamTarget, error)
+	Update(target *models.UpstreamTarget) (*models.UpstreamTarget, error)
+	Delete(automationID uuid.UUID, id uuid.UUID) error
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindByAutomation(automationID uuid.UUID) ([]*models.UpstreamTarget, error) {
+	var targets []*models.UpstreamTarget
+	err := r.DB.Where("automation_id = ?", automationID).Order("host asc, port asc").Find(&targets).Error
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (r *GormRepository) FindByID(automationID uuid.UUID, id uuid.UUID) (*models.UpstreamTarget, error) {
+	var target models.UpstreamTarget
+	err := r.DB.First(&target, "id = ? AND automation_id = ?", id, automationID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *GormRepository) Create(target *models.UpstreamTarget) (*models.UpstreamTarget, error) {
+	err := r.DB.Create(target).Error
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (r *GormRepository) Update(target *models.UpstreamTarget) (*models.UpstreamTarget, error) {
+	err := r.DB.Save(target).Error
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (r *GormRepository) Delete(automationID uuid.UUID, id uuid.UUID) error {
+	return r.DB.Where("automation_id = ?", automationID).Delete(&models.UpstreamTarget{}, id).Error
+}
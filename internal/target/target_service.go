@@ -0,0 +1,144 @@
+package target
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/events"
+	"automation-hub-backend/internal/models"
+	"automation-hub-backend/internal/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrTargetNotFound     = errors.New("target not found")
+	ErrInvalidTarget      = errors.New("invalid target")
+)
+
+type Service interface {
+	FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.UpstreamTarget, error)
+	Create(ctx context.Context, automationID uuid.UUID, target *models.UpstreamTarget) (*models.UpstreamTarget, error)
+	Update(ctx context.Context, automationID uuid.UUID, target *models.UpstreamTarget) (*models.UpstreamTarget, error)
+	Delete(ctx context.Context, automationID uuid.UUID, id uuid.UUID) error
+}
+
+type service struct {
+	repo           Repository
+	automationRepo automation.Repository
+	outbox         outbox.Repository
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, outboxRepo outbox.Repository) Service {
+	return &service{
+		repo:           repo,
+		automationRepo: automationRepo,
+		outbox:         outboxRepo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), outbox.DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context, automationID uuid.UUID) ([]*models.UpstreamTarget, error) {
+	if _, err := s.findAutomation(s.automationRepo, automationID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByAutomation(automationID)
+}
+
+func (s *service) Create(ctx context.Context, automationID uuid.UUID, target *models.UpstreamTarget) (*models.UpstreamTarget, error) {
+	target.ID = uuid.UUID{} // reset ID
+	target.AutomationID = automationID
+	if err := target.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+
+	var created *models.UpstreamTarget
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findAutomation(s.automationRepo.WithTx(tx), automationID); err != nil {
+			return err
+		}
+		var err error
+		created, err = s.repo.WithTx(tx).Create(target)
+		if err != nil {
+			return err
+		}
+		return s.publishChange(ctx, tx, automationID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *service) Update(ctx context.Context, automationID uuid.UUID, target *models.UpstreamTarget) (*models.UpstreamTarget, error) {
+	target.AutomationID = automationID
+	if err := target.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+
+	var updated *models.UpstreamTarget
+	err := s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if _, err := txRepo.FindByID(automationID, target.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTargetNotFound
+			}
+			return err
+		}
+		var err error
+		updated, err = txRepo.Update(target)
+		if err != nil {
+			return err
+		}
+		return s.publishChange(ctx, tx, automationID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Delete(ctx context.Context, automationID uuid.UUID, id uuid.UUID) error {
+	return s.automationRepo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if _, err := txRepo.FindByID(automationID, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTargetNotFound
+			}
+			return err
+		}
+		if err := txRepo.Delete(automationID, id); err != nil {
+			return err
+		}
+		return s.publishChange(ctx, tx, automationID)
+	})
+}
+
+// publishChange validates the automation with its new set of targets and
+// queues an update event carrying them.
+func (s *service) publishChange(ctx context.Context, tx *gorm.DB, automationID uuid.UUID) error {
+	current, err := s.findAutomation(s.automationRepo.WithTx(tx), automationID)
+	if err != nil {
+		return err
+	}
+	if err := current.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	return s.outbox.WithTx(tx).Enqueue(events.NewAutomationEvent(ctx, events.UpdateEvent, current))
+}
+
+func (s *service) findAutomation(repo automation.Repository, id uuid.UUID) (*models.Automation, error) {
+	current, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+	return current, nil
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"automation-hub-backend/internal/config"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	OTLPExporter   = "otlp"
+	StdoutExporter = "stdout"
+	NoExporter     = "none"
+
+	instrumentationName = "automation-hub-backend"
+)
+
+// propagator carries the W3C trace context over HTTP headers, Kafka headers
+// and the outbox.
+var propagator = propagation.TraceContext{}
+
+// Init installs the global tracer provider for the configured exporter. The
+// returned function flushes the pending spans and must be called on exit.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagator, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, config.AppConfig.TracingExporter)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(config.AppConfig.TracingServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.AppConfig.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, name string) (sdktrace.SpanExporter, error) {
+	switch name {
+	case OTLPExporter:
+		var options []otlptracehttp.Option
+		if config.AppConfig.TracingEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.AppConfig.TracingEndpoint))
+		}
+		if config.AppConfig.TracingInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	case StdoutExporter:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case NoExporter, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", name)
+	}
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start opens a span named after the operation, as a child of the span in ctx.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, options...)
+}
+
+// End records err, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes the trace context of ctx into carrier.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// Extract returns a copy of ctx that continues the trace found in carrier.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+// TraceParent returns the W3C traceparent and tracestate values for the span
+// in ctx, or empty strings when there is none.
+func TraceParent(ctx context.Context) (string, string) {
+	carrier := propagation.MapCarrier{}
+	Inject(ctx, carrier)
+	return carrier.Get("traceparent"), carrier.Get("tracestate")
+}
+
+// WithTraceParent returns a copy of ctx that continues the trace identified
+// by the W3C traceparent and tracestate values.
+func WithTraceParent(ctx context.Context, traceParent string, traceState string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return Extract(ctx, propagation.MapCarrier{"traceparent": traceParent, "tracestate": traceState})
+}
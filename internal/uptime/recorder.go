@@ -0,0 +1,49 @@
+package uptime
+
+import (
+	"automation-hub-backend/internal/healthcheck"
+	"automation-hub-backend/internal/models"
+)
+
+// Recorder stores every probe result as an uptime sample and turns status
+// changes into outages.
+type Recorder struct {
+	repo Repository
+}
+
+func NewRecorder(repo Repository) *Recorder {
+	return &Recorder{
+		repo: repo,
+	}
+}
+
+func DefaultRecorder() *Recorder {
+	return NewRecorder(DefaultRepository())
+}
+
+func (r *Recorder) Observe(result *healthcheck.Result) error {
+	err := r.repo.AddSample(&models.UptimeSample{
+		AutomationID: result.Automation.ID,
+		CheckedAt:    result.CheckedAt,
+		Up:           result.Err == nil,
+		LatencyMs:    result.Latency.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.Changed() {
+		return nil
+	}
+	switch result.Status.Status {
+	case models.HealthUnhealthy:
+		return r.repo.OpenOutage(&models.Outage{
+			AutomationID: result.Automation.ID,
+			StartedAt:    result.Since(),
+			Cause:        result.Status.LastError,
+		})
+	case models.HealthHealthy:
+		return r.repo.CloseOutage(result.Automation.ID, result.Since())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package uptime
+
+import (
+	"automation-hub-backend/internal/config"
+	"context"
+	"log"
+	"time"
+)
+
+// rollupInterval is how often samples are downsampled and history is pruned.
+const rollupInterval = time.Hour
+
+// RollupJob downsamples the uptime samples into hourly rollups and applies
+// the retention of samples, rollups and outages. Every step is idempotent,
+// so replicas may run it concurrently.
+type RollupJob struct {
+	repo            Repository
+	sampleRetention time.Duration
+	rollupRetention time.Duration
+}
+
+func NewRollupJob(repo Repository, sampleRetention time.Duration, rollupRetention time.Duration) *RollupJob {
+	return &RollupJob{
+		repo:            repo,
+		sampleRetention: sampleRetention,
+		rollupRetention: rollupRetention,
+	}
+}
+
+func DefaultRollupJob() *RollupJob {
+	return NewRollupJob(DefaultRepository(), config.AppConfig.UptimeSampleRetention,
+		config.AppConfig.UptimeRollupRetention)
+}
+
+func (j *RollupJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(rollupInterval)
+	defer ticker.Stop()
+	for {
+		j.run()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *RollupJob) run() {
+	now := time.Now().UTC()
+	if err := j.repo.Rollup(now.Truncate(time.Hour), now.Add(-j.sampleRetention).Truncate(time.Hour)); err != nil {
+		log.Printf("Failed to roll up uptime samples: %v", err)
+		// samples that were not rolled up yet must not be pruned
+		return
+	}
+
+	if deleted, err := j.repo.DeleteSamplesBefore(now.Add(-j.sampleRetention)); err != nil {
+		log.Printf("Failed to prune uptime samples: %v", err)
+	} else if deleted > 0 {
+		log.Printf("Removed %d uptime samples", deleted)
+	}
+	if _, err := j.repo.DeleteRollupsBefore(now.Add(-j.rollupRetention)); err != nil {
+		log.Printf("Failed to prune uptime rollups: %v", err)
+	}
+	if _, err := j.repo.DeleteOutagesBefore(now.Add(-j.rollupRetention)); err != nil {
+		log.Printf("Failed to prune outages: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package uptime
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// Uptime
+// @Summary Get the uptime of an automation
+// @Description Availability, latency percentiles and outages of an automation over a window
+// @Tags Uptime
+// @Produce  json
+// @Param id path string true "Automation ID"
+// @Param window query string false "Window such as 30d, 7d or 12h" default(30d)
+// @Success 200 {object} uptime.Report "Successfully retrieved uptime"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /automation/{id}/uptime [get]
+func (h *Handler) Uptime(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), id, c.Query("window"))
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrAutomationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidWindow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
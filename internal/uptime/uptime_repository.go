@@ -0,0 +1,154 @@
+package uptime
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Repository interface {
+	AddSample(sample *models.UptimeSample) error
+	OpenOutage(outage *models.Outage) error
+	CloseOutage(automationID uuid.UUID, endedAt time.Time) error
+	FindLatencies(automationID uuid.UUID, from time.Time, to time.Time) ([]int64, error)
+	CountSamples(automationID uuid.UUID, from time.Time, to time.Time) (int, int, error)
+	FindRollups(automationID uuid.UUID, from time.Time, to time.Time) ([]*models.UptimeRollup, error)
+	FindOutages(automationID uuid.UUID, from time.Time, to time.Time) ([]*models.Outage, error)
+	Rollup(before time.Time, fallback time.Time) error
+	DeleteSamplesBefore(before time.Time) (int64, error)
+	DeleteRollupsBefore(before time.Time) (int64, error)
+	DeleteOutagesBefore(before time.Time) (int64, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) AddSample(sample *models.UptimeSample) error {
+	return r.DB.Create(sample).Error
+}
+
+// OpenOutage records the start of an outage unless one is already open.
+func (r *GormRepository) OpenOutage(outage *models.Outage) error {
+	var open int64
+	err := r.DB.Model(&models.Outage{}).
+		Where("automation_id = ? AND ended_at IS NULL", outage.AutomationID).
+		Count(&open).Error
+	if err != nil || open > 0 {
+		return err
+	}
+	return r.DB.Create(outage).Error
+}
+
+func (r *GormRepository) CloseOutage(automationID uuid.UUID, endedAt time.Time) error {
+	return r.DB.Model(&models.Outage{}).
+		Where("automation_id = ? AND ended_at IS NULL", automationID).
+		Update("ended_at", endedAt).Error
+}
+
+// FindLatencies returns the latencies of the successful probes in [from, to).
+func (r *GormRepository) FindLatencies(automationID uuid.UUID, from time.Time, to time.Time) ([]int64, error) {
+	var latencies []int64
+	err := r.DB.Model(&models.UptimeSample{}).
+		Where("automation_id = ? AND checked_at >= ? AND checked_at < ? AND up", automationID, from, to).
+		Pluck("latency_ms", &latencies).Error
+	if err != nil {
+		return nil, err
+	}
+	return latencies, nil
+}
+
+// CountSamples returns the number of probes and failed probes in [from, to).
+func (r *GormRepository) CountSamples(automationID uuid.UUID, from time.Time, to time.Time) (int, int, error) {
+	var counts struct {
+		Checks   int
+		Failures int
+	}
+	err := r.DB.Model(&models.UptimeSample{}).
+		Select("COUNT(*) AS checks, COUNT(*) FILTER (WHERE NOT up) AS failures").
+		Where("automation_id = ? AND checked_at >= ? AND checked_at < ?", automationID, from, to).
+		Scan(&counts).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return counts.Checks, counts.Failures, nil
+}
+
+func (r *GormRepository) FindRollups(automationID uuid.UUID, from time.Time, to time.Time) ([]*models.UptimeRollup, error) {
+	var rollups []*models.UptimeRollup
+	err := r.DB.Where("automation_id = ? AND bucket_start >= ? AND bucket_start < ?", automationID, from, to).
+		Order("bucket_start asc").
+		Find(&rollups).Error
+	if err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+// FindOutages returns the outages overlapping [from, to).
+func (r *GormRepository) FindOutages(automationID uuid.UUID, from time.Time, to time.Time) ([]*models.Outage, error) {
+	var outages []*models.Outage
+	err := r.DB.Where("automation_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", automationID, to, from).
+		Order("started_at asc").
+		Find(&outages).Error
+	if err != nil {
+		return nil, err
+	}
+	return outages, nil
+}
+
+// Rollup summarises the samples of every hour that ended before before. It
+// resumes from the latest rollup, which is recomputed in case it was written
+// while its hour was still incomplete; without rollups it starts at fallback.
+func (r *GormRepository) Rollup(before time.Time, fallback time.Time) error {
+	var latest *time.Time
+	if err := r.DB.Model(&models.UptimeRollup{}).Select("MAX(bucket_start)").Scan(&latest).Error; err != nil {
+		return err
+	}
+	since := fallback
+	if latest != nil && latest.After(fallback) {
+		since = *latest
+	}
+
+	return r.DB.Exec(`INSERT INTO uptime_rollups (automation_id, bucket_start, checks, failures, latency_p50_ms, latency_p95_ms)
+		SELECT automation_id, date_trunc('hour', checked_at) AS bucket_start, COUNT(*), COUNT(*) FILTER (WHERE NOT up),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE up), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE up), 0)
+		FROM uptime_samples
+		WHERE checked_at >= ? AND checked_at < ?
+		GROUP BY automation_id, bucket_start
+		ON CONFLICT (automation_id, bucket_start) DO UPDATE SET checks = EXCLUDED.checks,
+			failures = EXCLUDED.failures, latency_p50_ms = EXCLUDED.latency_p50_ms,
+			latency_p95_ms = EXCLUDED.latency_p95_ms`, since, before).Error
+}
+
+func (r *GormRepository) DeleteSamplesBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("checked_at < ?", before).Delete(&models.UptimeSample{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormRepository) DeleteRollupsBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("bucket_start < ?", before).Delete(&models.UptimeRollup{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormRepository) DeleteOutagesBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("ended_at IS NOT NULL AND ended_at < ?", before).Delete(&models.Outage{})
+	return result.RowsAffected, result.Error
+}
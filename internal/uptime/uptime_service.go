@@ -0,0 +1,215 @@
+package uptime
+
+import (
+	"automation-hub-backend/internal/automation"
+	"automation-hub-backend/internal/config"
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultWindow = "30d"
+
+var (
+	ErrAutomationNotFound = errors.New("automation not found")
+	ErrInvalidWindow      = errors.New("invalid window")
+)
+
+// Report describes the reachability of an automation over a window.
+// Availability and the latency percentiles are nil when there were no
+// probes. Hours older than the sample retention are served from rollups, for
+// which the percentiles are approximated from the hourly percentiles.
+type Report struct {
+	AutomationID uuid.UUID         `json:"automationId"`
+	Window       string            `json:"window"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Checks       int               `json:"checks"`
+	Failures     int               `json:"failures"`
+	Availability *float64          `json:"availability"`
+	LatencyP50Ms *float64          `json:"latencyP50Ms"`
+	LatencyP95Ms *float64          `json:"latencyP95Ms"`
+	Outages      []*OutageInterval `json:"outages"`
+}
+
+// OutageInterval is an outage clipped to the report window.
+type OutageInterval struct {
+	StartedAt       time.Time  `json:"startedAt"`
+	EndedAt         *time.Time `json:"endedAt,omitempty"`
+	DurationSeconds int64      `json:"durationSeconds"`
+	Cause           string     `json:"cause,omitempty"`
+}
+
+type Service interface {
+	Report(ctx context.Context, automationID uuid.UUID, window string) (*Report, error)
+}
+
+type service struct {
+	repo            Repository
+	automationRepo  automation.Repository
+	sampleRetention time.Duration
+	rollupRetention time.Duration
+}
+
+func NewService(repo Repository, automationRepo automation.Repository, sampleRetention time.Duration,
+	rollupRetention time.Duration) Service {
+	return &service{
+		repo:            repo,
+		automationRepo:  automationRepo,
+		sampleRetention: sampleRetention,
+		rollupRetention: rollupRetention,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository(), automation.DefaultRepository(), config.AppConfig.UptimeSampleRetention,
+		config.AppConfig.UptimeRollupRetention)
+}
+
+func (s *service) Report(ctx context.Context, automationID uuid.UUID, window string) (*Report, error) {
+	if window == "" {
+		window = DefaultWindow
+	}
+	length, err := ParseWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	if length > s.rollupRetention {
+		return nil, fmt.Errorf("%w: history is only kept for %s", ErrInvalidWindow, s.rollupRetention)
+	}
+
+	if _, err := s.automationRepo.FindByID(automationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutomationNotFound
+		}
+		return nil, err
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-length)
+	// samples are complete from the first hour after the retention cutoff;
+	// everything before comes from the rollups
+	boundary := to.Add(-s.sampleRetention).Truncate(time.Hour).Add(time.Hour)
+	if boundary.Before(from) {
+		boundary = from
+	}
+
+	report := &Report{AutomationID: automationID, Window: window, From: from, To: to}
+	var p50, p95 []weighted
+
+	if from.Before(boundary) {
+		rollups, errRollups := s.repo.FindRollups(automationID, from.Truncate(time.Hour), boundary)
+		if errRollups != nil {
+			return nil, errRollups
+		}
+		for _, rollup := range rollups {
+			report.Checks += rollup.Checks
+			report.Failures += rollup.Failures
+			if successes := rollup.Checks - rollup.Failures; successes > 0 {
+				p50 = append(p50, weighted{value: rollup.LatencyP50Ms, weight: successes})
+				p95 = append(p95, weighted{value: rollup.LatencyP95Ms, weight: successes})
+			}
+		}
+	}
+
+	checks, failures, err := s.repo.CountSamples(automationID, boundary, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Checks += checks
+	report.Failures += failures
+
+	latencies, err := s.repo.FindLatencies(automationID, boundary, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, latency := range latencies {
+		p50 = append(p50, weighted{value: float64(latency), weight: 1})
+		p95 = append(p95, weighted{value: float64(latency), weight: 1})
+	}
+
+	if report.Checks > 0 {
+		availability := float64(report.Checks-report.Failures) / float64(report.Checks) * 100
+		report.Availability = &availability
+	}
+	report.LatencyP50Ms = percentile(p50, 0.5)
+	report.LatencyP95Ms = percentile(p95, 0.95)
+
+	outages, err := s.repo.FindOutages(automationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Outages = make([]*OutageInterval, 0, len(outages))
+	for _, outage := range outages {
+		report.Outages = append(report.Outages, clip(outage, from, to))
+	}
+	return report, nil
+}
+
+// ParseWindow parses a window such as "30d", "12h" or "90m".
+func ParseWindow(window string) (time.Duration, error) {
+	var (
+		length time.Duration
+		err    error
+	)
+	if days, found := strings.CutSuffix(window, "d"); found {
+		var n int
+		n, err = strconv.Atoi(days)
+		length = time.Duration(n) * 24 * time.Hour
+	} else {
+		length, err = time.ParseDuration(window)
+	}
+	if err != nil || length <= 0 {
+		return 0, fmt.Errorf("%w: %q, use a duration such as 30d or 12h", ErrInvalidWindow, window)
+	}
+	return length, nil
+}
+
+func clip(outage *models.Outage, from time.Time, to time.Time) *OutageInterval {
+	interval := &OutageInterval{StartedAt: outage.StartedAt, EndedAt: outage.EndedAt, Cause: outage.Cause}
+	start := outage.StartedAt
+	if start.Before(from) {
+		start = from
+	}
+	end := to
+	if outage.EndedAt != nil && outage.EndedAt.Before(to) {
+		end = *outage.EndedAt
+	}
+	interval.DurationSeconds = int64(end.Sub(start).Seconds())
+	return interval
+}
+
+type weighted struct {
+	value  float64
+	weight int
+}
+
+func percentile(values []weighted, q float64) *float64 {
+	total := 0
+	for _, v := range values {
+		total += v.weight
+	}
+	if total == 0 {
+		return nil
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].value < values[j].value
+	})
+	rank := q * float64(total)
+	seen := 0
+	for _, v := range values {
+		seen += v.weight
+		if float64(seen) >= rank {
+			return &v.value
+		}
+	}
+	return &values[len(values)-1].value
+}
@@ -0,0 +1,20 @@
+package workspace
+
+import (
+	"automation-hub-backend/internal/models"
+	"context"
+)
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx that carries the workspace a request
+// operates in.
+func WithWorkspace(ctx context.Context, workspace *models.Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// FromContext returns the workspace of the request, or nil outside one.
+func FromContext(ctx context.Context) *models.Workspace {
+	workspace, _ := ctx.Value(workspaceKey{}).(*models.Workspace)
+	return workspace
+}
@@ -0,0 +1,204 @@
+package workspace
+
+import (
+	"automation-hub-backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+// automationParams are the path parameters that name automations on the
+// routes of a workspace.
+var automationParams = []string{"id", "id1", "id2"}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func DefaultHandler() *Handler {
+	return NewHandler(DefaultService())
+}
+
+// Scope resolves the workspace named by the :ws path parameter, or the
+// default workspace on routes without one, and stores it in the request
+// context. Automations named in the path must belong to the workspace, so
+// their targets, health checks and history cannot be reached through
+// another workspace.
+func (h *Handler) Scope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("ws")
+		if slug == "" {
+			slug = models.DefaultWorkspaceSlug
+		}
+		workspace, err := h.service.FindBySlug(c.Request.Context(), slug)
+		if err != nil {
+			c.AbortWithStatusJSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		for _, param := range automationParams {
+			// Malformed IDs are left for the handler to report.
+			id, errParse := uuid.Parse(c.Param(param))
+			if errParse != nil {
+				continue
+			}
+			if err := h.service.CheckAutomation(c.Request.Context(), workspace, id); err != nil {
+				c.AbortWithStatusJSON(statusFor(err), gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.Request = c.Request.WithContext(WithWorkspace(c.Request.Context(), workspace))
+		c.Next()
+	}
+}
+
+// GetAll
+// @Summary Get all workspaces
+// @Description Retrieve every workspace, ordered by slug
+// @Tags Workspaces
+// @Produce  json
+// @Success 200 {array} models.Workspace "Successfully retrieved workspaces"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /workspaces [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	workspaces, err := h.service.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// Get
+// @Summary Get a workspace
+// @Description Retrieve a workspace by its slug
+// @Tags Workspaces
+// @Produce  json
+// @Param ws path string true "Workspace slug"
+// @Success 200 {object} models.Workspace "Successfully retrieved workspace"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /workspaces/{ws} [get]
+func (h *Handler) Get(c *gin.Context) {
+	workspace, err := h.service.FindBySlug(c.Request.Context(), c.Param("ws"))
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Create
+// @Summary Create a workspace
+// @Description Create a workspace; slugs are unique and cannot be changed later
+// @Tags Workspaces
+// @Accept  json
+// @Produce  json
+// @Param workspace body models.Workspace true "Workspace data"
+// @Success 201 {object} models.Workspace "Successfully created workspace"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /workspaces [post]
+func (h *Handler) Create(c *gin.Context) {
+	var workspace models.Workspace
+	if !readBody(c, &workspace) {
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), &workspace)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Update
+// @Summary Rename a workspace
+// @Description Change the name of a workspace; the slug is kept
+// @Tags Workspaces
+// @Accept  json
+// @Produce  json
+// @Param ws path string true "Workspace slug"
+// @Param workspace body models.Workspace true "Workspace data"
+// @Success 200 {object} models.Workspace "Successfully updated workspace"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /workspaces/{ws} [patch]
+func (h *Handler) Update(c *gin.Context) {
+	var workspace models.Workspace
+	if !readBody(c, &workspace) {
+		return
+	}
+
+	updated, err := h.service.Update(c.Request.Context(), c.Param("ws"), &workspace)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete
+// @Summary Delete a workspace
+// @Description Delete a workspace that holds no automations, not even in the trash
+// @Tags Workspaces
+// @Produce  json
+// @Param ws path string true "Workspace slug"
+// @Success 204 "Successfully deleted workspace"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /workspaces/{ws} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("ws")); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func readBody(c *gin.Context, value interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	defer c.Request.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+
+	if err := models.JSON.Unmarshal(body, value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrWorkspaceNotFound), errors.Is(err, ErrAutomationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidWorkspace), errors.Is(err, ErrDefaultWorkspace):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrWorkspaceExists), errors.Is(err, ErrSlugReserved), errors.Is(err, ErrWorkspaceNotEmpty):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"automation-hub-backend/internal/infra"
+	"automation-hub-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	WithTx(tx *gorm.DB) Repository
+	FindAll() ([]*models.Workspace, error)
+	FindBySlug(slug string) (*models.Workspace, error)
+	Create(workspace *models.Workspace) (*models.Workspace, error)
+	Update(workspace *models.Workspace) (*models.Workspace, error)
+	Delete(id uuid.UUID) error
+	CountAutomations(id uuid.UUID) (int64, error)
+	ContainsAutomation(id uuid.UUID, automationID uuid.UUID) (bool, error)
+	IsEnvironmentName(slug string) (bool, error)
+}
+
+type GormRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		DB: db,
+	}
+}
+
+func DefaultRepository() Repository {
+	db, err := infra.GetDefaultDB()
+	if err != nil {
+		panic(err)
+	}
+	return NewGormRepository(db)
+}
+
+func (r *GormRepository) WithTx(tx *gorm.DB) Repository {
+	return NewGormRepository(tx)
+}
+
+func (r *GormRepository) FindAll() ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	err := r.DB.Order("slug asc").Find(&workspaces).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *GormRepository) FindBySlug(slug string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	err := r.DB.First(&workspace, "slug = ?", slug).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *GormRepository) Create(workspace *models.Workspace) (*models.Workspace, error) {
+	err := r.DB.Create(workspace).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (r *GormRepository) Update(workspace *models.Workspace) (*models.Workspace, error) {
+	err := r.DB.Save(workspace).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (r *GormRepository) Delete(id uuid.UUID) error {
+	return r.DB.Delete(&models.Workspace{}, id).Error
+}
+
+// CountAutomations counts the automations of the workspace, including those
+// in the trash.
+func (r *GormRepository) CountAutomations(id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.DB.Unscoped().Model(&models.Automation{}).Where("workspace_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// IsEnvironmentName reports whether an automation has an environment named
+// like slug. Both are the first segment of gateway paths.
+func (r *GormRepository) IsEnvironmentName(slug string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.AutomationEnvironment{}).Where("name = ?", slug).Count(&count).Error
+	return count > 0, err
+}
+
+// ContainsAutomation reports whether the automation, in the trash or not,
+// belongs to the workspace.
+func (r *GormRepository) ContainsAutomation(id uuid.UUID, automationID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.DB.Unscoped().Model(&models.Automation{}).
+		Where("id = ? AND workspace_id = ?", automationID, id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"automation-hub-backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrWorkspaceExists    = errors.New("a workspace with this slug already exists")
+	ErrSlugReserved       = errors.New("the slug is the name of an environment")
+	ErrInvalidWorkspace   = errors.New("invalid workspace")
+	ErrWorkspaceNotEmpty  = errors.New("workspace still holds automations, including those in the trash")
+	ErrDefaultWorkspace   = errors.New("the default workspace cannot be deleted")
+	ErrAutomationNotFound = errors.New("automation not found")
+)
+
+type Service interface {
+	FindAll(ctx context.Context) ([]*models.Workspace, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Workspace, error)
+	Create(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	Update(ctx context.Context, slug string, workspace *models.Workspace) (*models.Workspace, error)
+	Delete(ctx context.Context, slug string) error
+	CheckAutomation(ctx context.Context, workspace *models.Workspace, automationID uuid.UUID) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func DefaultService() Service {
+	return NewService(DefaultRepository())
+}
+
+func (s *service) FindAll(ctx context.Context) ([]*models.Workspace, error) {
+	return s.repo.FindAll()
+}
+
+func (s *service) FindBySlug(ctx context.Context, slug string) (*models.Workspace, error) {
+	workspace, err := s.repo.FindBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (s *service) Create(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	workspace.ID = uuid.UUID{} // reset ID
+	if err := workspace.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+	}
+
+	_, err := s.repo.FindBySlug(workspace.Slug)
+	if err == nil {
+		return nil, ErrWorkspaceExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	// gateway paths start with the workspace slug or the environment name
+	reserved, err := s.repo.IsEnvironmentName(workspace.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, ErrSlugReserved
+	}
+	return s.repo.Create(workspace)
+}
+
+// Update renames a workspace. The slug is kept, since it is part of the URLs,
+// gateway paths and topics of the workspace.
+func (s *service) Update(ctx context.Context, slug string, workspace *models.Workspace) (*models.Workspace, error) {
+	current, err := s.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	current.Name = workspace.Name
+	if err := current.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+	}
+	return s.repo.Update(current)
+}
+
+// Delete removes an empty workspace. Automations in the trash count, as they
+// could still be restored into it.
+func (s *service) Delete(ctx context.Context, slug string) error {
+	current, err := s.FindBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if current.IsDefault() {
+		return ErrDefaultWorkspace
+	}
+
+	count, err := s.repo.CountAutomations(current.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrWorkspaceNotEmpty
+	}
+	return s.repo.Delete(current.ID)
+}
+
+// CheckAutomation fails with ErrAutomationNotFound unless the automation
+// belongs to the workspace.
+func (s *service) CheckAutomation(ctx context.Context, workspace *models.Workspace, automationID uuid.UUID) error {
+	contained, err := s.repo.ContainsAutomation(workspace.ID, automationID)
+	if err != nil {
+		return err
+	}
+	if !contained {
+		return ErrAutomationNotFound
+	}
+	return nil
+}